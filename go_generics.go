@@ -375,3 +375,5410 @@ func main() {
         fmt.Println("Size:", sortedItem.size())
     }
 }
+
+
+/////////////////////////////////////////////////////////////////////////
+// Listing 13: Generisches Parsen von String-Slices mit Fehlersammlung //
+/////////////////////////////////////////////////////////////////////////
+
+// Describes a single item that could not be parsed. Index refers to the
+// position of the item in the original string slice.
+type ParseError struct {
+    Index int
+    Err   error
+}
+
+func (e ParseError) Error() string {
+    return fmt.Sprintf("item %d: %v", e.Index, e.Err)
+}
+
+// Parses all strings using the given generic parse function. In contrast to
+// processInterface, parsing does not stop at the first failure. All successfully
+// parsed items are returned together with the collected parse errors.
+func ParseSlice[T any](items []string, parse func(string) (T, error)) ([]T, []ParseError) {
+    result := make([]T, 0, len(items))
+    var errs []ParseError
+    for index, item := range items {
+        parsed, err := parse(item)
+        if err != nil {
+            errs = append(errs, ParseError{Index: index, Err: err})
+            continue
+        }
+
+        result = append(result, parsed)
+    }
+
+    return result, errs
+}
+
+func main() {
+    // Parse lentil sizes from command line arguments and hand them over to
+    // the type-safe process function.
+    sizes, errs := ParseSlice(os.Args[1:], strconv.Atoi)
+    for _, err := range errs {
+        fmt.Println("Ignoring", err)
+    }
+    largeSizes := process(sizes, func(size int) bool { return size >= LARGE })
+    fmt.Println("Large:", len(largeSizes), "Other:", len(sizes)-len(largeSizes))
+}
+
+
+////////////////////////////////////////////////////////////
+// Listing 14: Laufendes Maximum und Minimum auf Channels //
+////////////////////////////////////////////////////////////
+
+// Emits the largest item seen so far after each item received from input
+func RollingMax[T constraints.Ordered](input <-chan T) <-chan T {
+    return rolling(input, func(current, candidate T) bool { return candidate > current })
+}
+
+// Emits the smallest item seen so far after each item received from input
+func RollingMin[T constraints.Ordered](input <-chan T) <-chan T {
+    return rolling(input, func(current, candidate T) bool { return candidate < current })
+}
+
+// Emits the item with the highest key seen so far. Like in bubblesort, the
+// key function turns each item into a type compatible with Ordered.
+func RollingMaxBy[I any, O constraints.Ordered](input <-chan I, key func(I) O) <-chan I {
+    return rolling(input, func(current, candidate I) bool { return key(candidate) > key(current) })
+}
+
+// Shared implementation for rolling extremes. replaces returns true if
+// candidate should become the new current extreme.
+func rolling[T any](input <-chan T, replaces func(current, candidate T) bool) <-chan T {
+    out := make(chan T)
+    go func() {
+        defer close(out)
+        var current T
+        first := true
+        for item := range input {
+            if first || replaces(current, item) {
+                current = item
+                first = false
+            }
+            out <- current
+        }
+    }()
+    return out
+}
+
+func main() {
+    in := make(chan sizedLentil, 3)
+    in <- sizedLentil{lentilSize: MEDIUM}
+    in <- sizedLentil{lentilSize: SMALL}
+    in <- sizedLentil{lentilSize: LARGE}
+    close(in)
+
+    for largest := range RollingMaxBy(in, func(item sizedLentil) int { return item.size() }) {
+        fmt.Println("Largest so far:", largest.size())
+    }
+}
+
+
+////////////////////////////////////////////////////////////
+// Listing 15: Nicht blockierende Inspektion von Channels //
+////////////////////////////////////////////////////////////
+
+// Snapshot of a channel's state. Note that a successful probe consumes
+// Item from the channel, it is not put back.
+type ProbeResult[T any] struct {
+    Len      int
+    Cap      int
+    Item     T
+    HasItem  bool
+    IsClosed bool // Best effort, only detected if the channel is already drained
+}
+
+// Inspects the channel without blocking. Tries to read a single item.
+func Probe[T any](ch <-chan T) ProbeResult[T] {
+    result := ProbeResult[T]{Len: len(ch), Cap: cap(ch)}
+    select {
+    case item, ok := <-ch:
+        if ok {
+            result.Item, result.HasItem = item, true
+        } else {
+            result.IsClosed = true
+        }
+    default:
+        // Nothing available right now, channel is empty or unbuffered
+    }
+
+    return result
+}
+
+// Drains up to maxItems items from the channel using non-blocking reads.
+// Stops as soon as no item is immediately available.
+func ProbeAll[T any](ch <-chan T, maxItems int) []T {
+    result := make([]T, 0)
+    for len(result) < maxItems {
+        probe := Probe(ch)
+        if !probe.HasItem {
+            break
+        }
+        result = append(result, probe.Item)
+    }
+
+    return result
+}
+
+
+//////////////////////////////////////////////////////
+// Listing 16: Zeitgesteuerte Ausgabe von Elementen //
+//////////////////////////////////////////////////////
+
+// Identifies a scheduled item so that it can be cancelled
+type ScheduleID int64
+
+type scheduledItem[T any] struct {
+    id   ScheduleID
+    at   time.Time
+    item T
+}
+
+// Emits items on its channel at the time they have been scheduled for.
+type Scheduler[T any] struct {
+    mu      sync.Mutex
+    queue   *PriorityQueue[scheduledItem[T]]
+    pending map[ScheduleID]struct{}
+    nextID  ScheduleID
+    wakeup  chan struct{}
+    done    chan struct{}
+    once    sync.Once
+    out     chan T
+}
+
+func NewScheduler[T any]() *Scheduler[T] {
+    s := &Scheduler[T]{
+        queue:   NewPriorityQueue(func(lhs, rhs scheduledItem[T]) bool { return lhs.at.Before(rhs.at) }),
+        pending: make(map[ScheduleID]struct{}),
+        wakeup:  make(chan struct{}, 1),
+        done:    make(chan struct{}),
+        out:     make(chan T),
+    }
+    go s.run()
+    return s
+}
+
+// Schedules item for emission at the given time. Items scheduled in the
+// past are emitted immediately.
+func (s *Scheduler[T]) Schedule(at time.Time, item T) ScheduleID {
+    s.mu.Lock()
+    s.nextID++
+    id := s.nextID
+    s.queue.Push(scheduledItem[T]{id: id, at: at, item: item})
+    s.pending[id] = struct{}{}
+    s.mu.Unlock()
+
+    // Wake up the scheduling goroutine, the new item might be due earlier
+    select {
+    case s.wakeup <- struct{}{}:
+    default:
+    }
+    return id
+}
+
+func (s *Scheduler[T]) ScheduleAfter(d time.Duration, item T) ScheduleID {
+    return s.Schedule(time.Now().Add(d), item)
+}
+
+// Cancels a scheduled item. Returns false if the item has already been
+// emitted or cancelled.
+func (s *Scheduler[T]) Cancel(id ScheduleID) bool {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    _, ok := s.pending[id]
+    delete(s.pending, id)
+    return ok
+}
+
+// Emits a new item created by factory every interval until the returned
+// function is called.
+func (s *Scheduler[T]) RecurringSchedule(interval time.Duration, factory func() T) func() {
+    stop := make(chan struct{})
+    var once sync.Once
+    go func() {
+        ticker := time.NewTicker(interval)
+        defer ticker.Stop()
+        for {
+            select {
+            case <-ticker.C:
+                s.Schedule(time.Now(), factory())
+            case <-stop:
+                return
+            case <-s.done:
+                return
+            }
+        }
+    }()
+    return func() { once.Do(func() { close(stop) }) }
+}
+
+func (s *Scheduler[T]) Channel() <-chan T { return s.out }
+
+// Stops the scheduler and closes its channel. Pending items are discarded.
+func (s *Scheduler[T]) Stop() {
+    s.once.Do(func() { close(s.done) })
+}
+
+func (s *Scheduler[T]) run() {
+    defer close(s.out)
+    for {
+        s.mu.Lock()
+
+        // Skip items that have been cancelled in the meantime
+        for next, ok := s.queue.Peek(); ok; next, ok = s.queue.Peek() {
+            if _, ok := s.pending[next.id]; ok {
+                break
+            }
+            s.queue.Pop()
+        }
+
+        var timer *time.Timer
+        var due <-chan time.Time
+        if next, ok := s.queue.Peek(); ok {
+            wait := time.Until(next.at)
+            if wait <= 0 {
+                s.queue.Pop()
+                delete(s.pending, next.id)
+                s.mu.Unlock()
+                select {
+                case s.out <- next.item:
+                case <-s.done:
+                    return
+                }
+                continue
+            }
+            timer = time.NewTimer(wait)
+            due = timer.C
+        }
+        s.mu.Unlock()
+
+        select {
+        case <-due:
+        case <-s.wakeup:
+        case <-s.done:
+            if timer != nil {
+                timer.Stop()
+            }
+            return
+        }
+        if timer != nil {
+            timer.Stop()
+        }
+    }
+}
+
+
+/////////////////////////////////////////////////
+// Listing 17: Middleware für Filterfunktionen //
+/////////////////////////////////////////////////
+
+// Wraps a filter function, just like HTTP middleware wraps handlers
+type Middleware[T any] func(next func(T) bool) func(T) bool
+
+// Logs each item together with the filter's decision
+func WithLogging[T any](logger *log.Logger) Middleware[T] {
+    return func(next func(T) bool) func(T) bool {
+        return func(item T) bool {
+            keep := next(item)
+            logger.Printf("item %v, keep: %t", item, keep)
+            return keep
+        }
+    }
+}
+
+// Counts the number of items the filter has been called for
+func WithMetrics[T any](counter *atomic.Int64) Middleware[T] {
+    return func(next func(T) bool) func(T) bool {
+        return func(item T) bool {
+            counter.Add(1)
+            return next(item)
+        }
+    }
+}
+
+// Drops the item if the filter does not come to a decision within d. Note
+// that the wrapped filter keeps running in the background after a timeout.
+func WithTimeout[T any](d time.Duration) Middleware[T] {
+    return func(next func(T) bool) func(T) bool {
+        return func(item T) bool {
+            // Buffered so that the goroutine can finish even after a timeout
+            result := make(chan bool, 1)
+            go func() { result <- next(item) }()
+
+            timer := time.NewTimer(d)
+            defer timer.Stop()
+            select {
+            case keep := <-result:
+                return keep
+            case <-timer.C:
+                return false
+            }
+        }
+    }
+}
+
+// Combines multiple middlewares into one. The first middleware is the
+// outermost one, i.e. it sees the item first.
+func Chain[T any](middlewares ...Middleware[T]) Middleware[T] {
+    return func(next func(T) bool) func(T) bool {
+        for i := len(middlewares) - 1; i >= 0; i-- {
+            next = middlewares[i](next)
+        }
+        return next
+    }
+}
+
+// Applies all middlewares to the filter function
+func WrapProcess[T any](filter func(T) bool, mw ...Middleware[T]) func(T) bool {
+    return Chain(mw...)(filter)
+}
+
+func main() {
+    items := []eatOrKeep{ /*...*/ }
+    /* ... */
+
+    var evaluated atomic.Int64
+    filter := WrapProcess(
+        func(item eatOrKeep) bool { return !item.shouldEat() },
+        WithLogging[eatOrKeep](log.Default()),
+        WithMetrics[eatOrKeep](&evaluated))
+    processedItems := process(items, filter)
+    fmt.Println("Evaluated:", evaluated.Load(), "Kept:", len(processedItems))
+}
+
+
+////////////////////////////////////////////
+// Listing 18: Wiederholungen mit Backoff //
+////////////////////////////////////////////
+
+// Calculates the delay before the next retry. Implementations are stateful,
+// each call to Next advances to the next delay.
+type Backoff interface {
+    Next() time.Duration
+}
+
+type exponentialBackoff struct {
+    current    time.Duration
+    max        time.Duration
+    multiplier float64
+}
+
+// Starts with initial and multiplies the delay after each retry, capped at max
+func ExponentialBackoff(initial, max time.Duration, multiplier float64) Backoff {
+    return &exponentialBackoff{current: initial, max: max, multiplier: multiplier}
+}
+
+func (b *exponentialBackoff) Next() time.Duration {
+    delay := b.current
+    b.current = time.Duration(float64(b.current) * b.multiplier)
+    if b.current > b.max {
+        b.current = b.max
+    }
+    return delay
+}
+
+type constantBackoff time.Duration
+
+// Always waits for d
+func ConstantBackoff(d time.Duration) Backoff { return constantBackoff(d) }
+
+func (b constantBackoff) Next() time.Duration { return time.Duration(b) }
+
+type linearBackoff struct {
+    current time.Duration
+    step    time.Duration
+}
+
+// Starts with initial and adds step after each retry
+func LinearBackoff(initial, step time.Duration) Backoff {
+    return &linearBackoff{current: initial, step: step}
+}
+
+func (b *linearBackoff) Next() time.Duration {
+    delay := b.current
+    b.current += b.step
+    return delay
+}
+
+// Waits for d or until ctx is done. Returns false if ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+    if ctx.Err() != nil {
+        return false
+    }
+
+    timer := time.NewTimer(d)
+    defer timer.Stop()
+    select {
+    case <-timer.C:
+        return true
+    case <-ctx.Done():
+        return false
+    }
+}
+
+// Calls produce until it succeeds, shouldRetry returns false for the error
+// or ctx is done (e.g. because its deadline has been exceeded).
+func RetryUntil[T any](ctx context.Context, produce func(context.Context) (T, error), shouldRetry func(error) bool, backoff Backoff) (T, error) {
+    for {
+        result, err := produce(ctx)
+        if err == nil {
+            return result, nil
+        }
+        if !shouldRetry(err) {
+            return result, err
+        }
+        if !sleepContext(ctx, backoff.Next()) {
+            var zero T
+            return zero, errors.Join(err, ctx.Err())
+        }
+    }
+}
+
+// Channel source that is reopened whenever it fails to open or gets closed.
+// Items of all opened channels are forwarded to the returned channel until
+// ctx is done.
+func RetryChannel[T any](ctx context.Context, open func(context.Context) (<-chan T, error), backoff Backoff) <-chan T {
+    out := make(chan T)
+    go func() {
+        defer close(out)
+        for {
+            if in, err := open(ctx); err == nil {
+            forward:
+                for {
+                    // Also wait for ctx while the source is idle, so a
+                    // cancelled context never hangs on a silent channel.
+                    select {
+                    case item, ok := <-in:
+                        if !ok {
+                            break forward
+                        }
+                        select {
+                        case out <- item:
+                        case <-ctx.Done():
+                            return
+                        }
+                    case <-ctx.Done():
+                        return
+                    }
+                }
+            }
+            if !sleepContext(ctx, backoff.Next()) {
+                return
+            }
+        }
+    }()
+    return out
+}
+
+
+//////////////////////////////////////////////////////////////
+// Listing 19: Iteratoren mit unterschiedlichen Fähigkeiten //
+//////////////////////////////////////////////////////////////
+
+// Iterates over items in forward direction only. In addition to Iterator
+// (see listing 111), it can tell whether there are more items.
+type ForwardIterator[T any] interface {
+    Iterator[T]
+    HasNext() bool
+}
+
+// Iterator that can also move backwards
+type BidirectionalIterator[T any] interface {
+    ForwardIterator[T]
+    // Returns the previous item and false if we are at the beginning
+    Prev() (T, bool)
+    HasPrev() bool
+}
+
+// Iterator with constant-time access to arbitrary positions
+type RandomAccessIterator[T any] interface {
+    BidirectionalIterator[T]
+    // Returns the item at offset i relative to the current position
+    At(i int) T
+    // Moves the iterator by n positions (negative n moves backwards)
+    Advance(n int)
+    // Current position of the iterator
+    Index() int
+    // Number of positions from this iterator to other
+    Distance(other RandomAccessIterator[T]) int
+}
+
+// Iterator over a slice. The position is always between two items, Next
+// returns the item after it, Prev the item before it.
+type sliceIterator[T any] struct {
+    items    []T
+    position int
+}
+
+func SliceBidirectional[T any](items []T) BidirectionalIterator[T] {
+    return &sliceIterator[T]{items: items}
+}
+
+func SliceRandomAccess[T any](items []T) RandomAccessIterator[T] {
+    return &sliceIterator[T]{items: items}
+}
+
+func (it *sliceIterator[T]) HasNext() bool { return it.position < len(it.items) }
+func (it *sliceIterator[T]) HasPrev() bool { return it.position > 0 }
+
+func (it *sliceIterator[T]) Next() (T, bool) {
+    if !it.HasNext() {
+        var zero T
+        return zero, false
+    }
+    it.position++
+    return it.items[it.position-1], true
+}
+
+func (it *sliceIterator[T]) Prev() (T, bool) {
+    if !it.HasPrev() {
+        var zero T
+        return zero, false
+    }
+    it.position--
+    return it.items[it.position], true
+}
+
+func (it *sliceIterator[T]) At(i int) T { return it.items[it.position+i] }
+
+func (it *sliceIterator[T]) Advance(n int) {
+    // Clamp position to the valid range [0, len(items)]
+    it.position = min(max(it.position+n, 0), len(it.items))
+}
+
+func (it *sliceIterator[T]) Index() int { return it.position }
+
+func (it *sliceIterator[T]) Distance(other RandomAccessIterator[T]) int {
+    return other.Index() - it.position
+}
+
+func main() {
+    items := []eatOrKeep{ /*...*/ }
+    /* ... */
+
+    // Iterate backwards over all items
+    iter := SliceRandomAccess(items)
+    iter.Advance(len(items))
+    for item, ok := iter.Prev(); ok; item, ok = iter.Prev() {
+        fmt.Println("Should eat:", item.shouldEat())
+    }
+}
+
+
+///////////////////////////////////////////////////////////////////
+// Listing 20: Observable mit Filter-, Map- und Merge-Operatoren //
+///////////////////////////////////////////////////////////////////
+
+// Push-based stream of items. source is called once per subscription and
+// pushes all items to the given subscriber function.
+type Observable[T any] struct {
+    source func(subscriber func(T))
+}
+
+func NewObservable[T any](source func(subscriber func(T))) *Observable[T] {
+    return &Observable[T]{source: source}
+}
+
+// Only passes items for which filter returns true, like process does for slices
+func (o *Observable[T]) Filter(filter func(T) bool) *Observable[T] {
+    return NewObservable(func(subscriber func(T)) {
+        o.source(func(item T) {
+            if filter(item) {
+                subscriber(item)
+            }
+        })
+    })
+}
+
+// Transforms all items. Implemented as a function because methods cannot
+// have additional type parameters.
+func MapObservable[T, O any](o *Observable[T], transform func(T) O) *Observable[O] {
+    return NewObservable(func(subscriber func(O)) {
+        o.source(func(item T) { subscriber(transform(item)) })
+    })
+}
+
+// Emits the items of both observables. The subscriber is never called
+// concurrently even though both sources run in parallel.
+func (o *Observable[T]) Merge(other *Observable[T]) *Observable[T] {
+    return NewObservable(func(subscriber func(T)) {
+        var mu sync.Mutex
+        synchronized := func(item T) {
+            mu.Lock()
+            defer mu.Unlock()
+            subscriber(item)
+        }
+
+        // Panics of the sources happen on our goroutines, so recover them
+        // there and re-panic on the subscribing goroutine. Only then can
+        // Subscribe report them via onError.
+        var failure any
+        var failureOnce sync.Once
+        var wg sync.WaitGroup
+        for _, source := range []func(func(T)){o.source, other.source} {
+            wg.Add(1)
+            go func(source func(func(T))) {
+                defer wg.Done()
+                defer func() {
+                    if r := recover(); r != nil {
+                        failureOnce.Do(func() { failure = r })
+                    }
+                }()
+                source(synchronized)
+            }(source)
+        }
+        wg.Wait()
+
+        if failure != nil {
+            panic(failure)
+        }
+    })
+}
+
+// Drops all items emitted after ctx is done
+func (o *Observable[T]) TakeUntil(ctx context.Context) *Observable[T] {
+    return NewObservable(func(subscriber func(T)) {
+        o.source(func(item T) {
+            if ctx.Err() == nil {
+                subscriber(item)
+            }
+        })
+    })
+}
+
+// Runs the observable in the background. onNext is called for every item,
+// onComplete when the source has finished and onError if the source panics.
+// After calling the returned cancel function, no further callbacks are
+// started. A callback that is already running when cancel is called still
+// completes.
+func (o *Observable[T]) Subscribe(onNext func(T), onError func(error), onComplete func()) (cancel func()) {
+    var cancelled atomic.Bool
+    go func() {
+        defer func() {
+            if r := recover(); r != nil {
+                if !cancelled.Load() && onError != nil {
+                    onError(fmt.Errorf("observable source failed: %v", r))
+                }
+                return
+            }
+            if !cancelled.Load() && onComplete != nil {
+                onComplete()
+            }
+        }()
+
+        o.source(func(item T) {
+            if !cancelled.Load() {
+                onNext(item)
+            }
+        })
+    }()
+    return func() { cancelled.Store(true) }
+}
+
+func main() {
+    lentils := NewObservable(func(subscriber func(lentil)) {
+        subscriber(lentil{isGood: true})
+        subscriber(lentil{isGood: false})
+    })
+    snails := NewObservable(func(subscriber func(snail)) {
+        subscriber(snail{hasHouse: true})
+    })
+
+    done := make(chan struct{})
+    MapObservable(lentils, func(l lentil) eatOrKeep { return l }).
+        Merge(MapObservable(snails, func(s snail) eatOrKeep { return s })).
+        Filter(func(item eatOrKeep) bool { return !item.shouldEat() }).
+        Subscribe(
+            func(item eatOrKeep) { fmt.Println("Kept:", item) },
+            func(err error) { fmt.Println("Error:", err) },
+            func() { close(done) })
+    <-done
+}
+
+
+//////////////////////////////////////////////////////////////////////////
+// Listing 21: Typsicheres Select über Channels unterschiedlicher Typen //
+//////////////////////////////////////////////////////////////////////////
+
+// One case of a select statement. Cases are created with CaseRecv, CaseSend
+// and CaseDefault, reflection stays hidden inside of this listing.
+type SelectCase interface {
+    reflectCase() reflect.SelectCase
+    handle(received reflect.Value, ok bool)
+}
+
+type recvCase[T any] struct {
+    ch      <-chan T
+    handler func(T)
+}
+
+func (c recvCase[T]) reflectCase() reflect.SelectCase {
+    return reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(c.ch)}
+}
+
+func (c recvCase[T]) handle(received reflect.Value, ok bool) {
+    // Handler is not called if the channel has been closed
+    if ok && c.handler != nil {
+        // Set via reflection instead of a type assertion, which would panic
+        // for a nil value if T is an interface type
+        var item T
+        reflect.ValueOf(&item).Elem().Set(received)
+        c.handler(item)
+    }
+}
+
+// Receives from ch and passes the received item to handler
+func CaseRecv[T any](ch <-chan T, handler func(T)) SelectCase {
+    return recvCase[T]{ch: ch, handler: handler}
+}
+
+type sendCase[T any] struct {
+    ch      chan<- T
+    value   T
+    handler func()
+}
+
+func (c sendCase[T]) reflectCase() reflect.SelectCase {
+    return reflect.SelectCase{Dir: reflect.SelectSend, Chan: reflect.ValueOf(c.ch), Send: reflect.ValueOf(&c.value).Elem()}
+}
+
+func (c sendCase[T]) handle(reflect.Value, bool) {
+    if c.handler != nil {
+        c.handler()
+    }
+}
+
+// Sends value to ch and calls handler afterwards
+func CaseSend[T any](ch chan<- T, value T, handler func()) SelectCase {
+    return sendCase[T]{ch: ch, value: value, handler: handler}
+}
+
+type defaultCase struct {
+    handler func()
+}
+
+func (c defaultCase) reflectCase() reflect.SelectCase {
+    return reflect.SelectCase{Dir: reflect.SelectDefault}
+}
+
+func (c defaultCase) handle(reflect.Value, bool) {
+    if c.handler != nil {
+        c.handler()
+    }
+}
+
+// Called if no other case is ready
+func CaseDefault(handler func()) SelectCase {
+    return defaultCase{handler: handler}
+}
+
+// Blocks until one of the cases can proceed and runs its handler. Returns
+// the index of the chosen case and false if it received from a closed channel.
+func Select(cases ...SelectCase) (int, bool) {
+    reflectCases := make([]reflect.SelectCase, len(cases))
+    for i, c := range cases {
+        reflectCases[i] = c.reflectCase()
+    }
+
+    chosen, received, ok := reflect.Select(reflectCases)
+    if reflectCases[chosen].Dir != reflect.SelectRecv {
+        ok = true
+    }
+    cases[chosen].handle(received, ok)
+    return chosen, ok
+}
+
+func main() {
+    lentils := make(chan lentil, 1)
+    snails := make(chan snail, 1)
+    snails <- snail{hasHouse: true}
+
+    Select(
+        CaseRecv(lentils, func(l lentil) { fmt.Println("Lentil, should eat:", l.shouldEat()) }),
+        CaseRecv(snails, func(s snail) { fmt.Println("Snail, should eat:", s.shouldEat()) }),
+        CaseDefault(func() { fmt.Println("Nothing to eat") }))
+}
+
+
+//////////////////////////////////////////////////
+// Listing 22: Filter mit Zugriff auf den Index //
+//////////////////////////////////////////////////
+
+// Like process, but the filter function also receives the item's index
+func ProcessWithIndex[I any](items []I, filter func(int, I) bool) []I {
+    result := []I{}
+    for index, item := range items {
+        if filter(index, item) {
+            result = append(result, item)
+        }
+    }
+
+    return result
+}
+
+// Like processChannel, but the filter function also receives a counter. It
+// starts at 0 and is incremented for every received item, no matter whether
+// the item has been kept or not.
+func ProcessChannelWithIndex[I any](items <-chan I, filter func(int, I) bool) <-chan I {
+    out := make(chan I)
+    go func() {
+        defer close(out)
+        index := 0
+        for item := range items {
+            if filter(index, item) {
+                out <- item
+            }
+            index++
+        }
+    }()
+    return out
+}
+
+func main() {
+    items := []eatOrKeep{ /*...*/ }
+    /* ... */
+
+    // Skip the first item, keep every second one
+    processedItems := ProcessWithIndex(items, func(index int, item eatOrKeep) bool { return index > 0 && index%2 == 0 })
+    fmt.Println("Eaten:", len(items)-len(processedItems), "Kept:", len(processedItems))
+}
+
+
+//////////////////////////////////////////////////////
+// Listing 23: Begrenzung auf die ersten n Elemente //
+//////////////////////////////////////////////////////
+
+// Reads and discards all remaining items so that the goroutine writing to
+// input does not block forever. Returns immediately, draining happens in
+// the background.
+func GracefulDrain[T any](input <-chan T) {
+    go func() {
+        for range input {
+        }
+    }()
+}
+
+// Forwards the first n items of input and closes the output afterwards.
+// Remaining items are drained so that upstream stages do not leak.
+func Limit[T any](input <-chan T, n int) <-chan T {
+    out := make(chan T)
+    go func() {
+        defer close(out)
+        defer GracefulDrain(input)
+        for count := 0; count < n; count++ {
+            item, ok := <-input
+            if !ok {
+                return
+            }
+            out <- item
+        }
+    }()
+    return out
+}
+
+// Returns the first n items of the slice (or all if there are fewer)
+func LimitSlice[T any](items []T, n int) []T {
+    return Take(items, n)
+}
+
+
+/////////////////////////////////////////////////////////
+// Listing 24: Überwachung des Füllstands von Channels //
+/////////////////////////////////////////////////////////
+
+// Reported whenever the buffer of the input channel crosses one of the
+// configured marks. Current <= Low means the channel is nearly empty,
+// Current >= High means backpressure is building up.
+type WatermarkEvent struct {
+    High, Low, Current int
+}
+
+// Passes all items through unchanged while watching the number of items
+// buffered in input. If nobody reads an event in time, it is replaced by the
+// next one, so monitoring never slows down the pipeline and a reader always
+// sees the latest crossing.
+func Watermark[T any](input <-chan T, lowMark, highMark int) (<-chan T, <-chan WatermarkEvent) {
+    out := make(chan T)
+    events := make(chan WatermarkEvent, 1)
+    go func() {
+        defer close(out)
+        defer close(events)
+
+        // -1 = below low mark, 0 = between marks, 1 = above high mark
+        state := 0
+        for item := range input {
+            current := len(input)
+            newState := 0
+            switch {
+            case current <= lowMark:
+                newState = -1
+            case current >= highMark:
+                newState = 1
+            }
+
+            if newState != state && newState != 0 {
+                event := WatermarkEvent{High: highMark, Low: lowMark, Current: current}
+                select {
+                case events <- event:
+                default:
+                    // Replace the unread event, the latest state matters most.
+                    // This goroutine is the only sender, so after draining
+                    // there is room in the buffer.
+                    select {
+                    case <-events:
+                    default:
+                    }
+                    events <- event
+                }
+            }
+            state = newState
+
+            out <- item
+        }
+    }()
+    return out, events
+}
+
+
+///////////////////////////////////////////////////////////
+// Listing 25: Kontextinformationen in Channel-Pipelines //
+///////////////////////////////////////////////////////////
+
+// Item plus request-scoped metadata (trace IDs, deadlines, etc.)
+type ContextItem[T any] struct {
+    Ctx  context.Context
+    Item T
+}
+
+// Like processChannel, but the filter has access to the item's context.
+// Items whose context has been cancelled are dropped.
+func ContextProcess[I any](items <-chan ContextItem[I], filter func(context.Context, I) bool) <-chan ContextItem[I] {
+    out := make(chan ContextItem[I])
+    go func() {
+        defer close(out)
+        for item := range items {
+            if item.Ctx.Err() == nil && filter(item.Ctx, item.Item) {
+                out <- item
+            }
+        }
+    }()
+    return out
+}
+
+// Transforms each item while keeping its context. Items whose context has
+// been cancelled are dropped.
+func ContextTransform[I, O any](items <-chan ContextItem[I], transform func(context.Context, I) O) <-chan ContextItem[O] {
+    out := make(chan ContextItem[O])
+    go func() {
+        defer close(out)
+        for item := range items {
+            if item.Ctx.Err() != nil {
+                continue
+            }
+            out <- ContextItem[O]{Ctx: item.Ctx, Item: transform(item.Ctx, item.Item)}
+        }
+    }()
+    return out
+}
+
+
+///////////////////////////////////////////////////////
+// Listing 26: Pausieren und Fortsetzen von Channels //
+///////////////////////////////////////////////////////
+
+// Defines what happens to new items while paused and the buffer is full
+type OverflowPolicy int
+
+const (
+    // Stop reading from the input until there is room in the buffer again
+    OverflowBlock OverflowPolicy = iota
+    // Discard new items
+    OverflowDrop
+)
+
+// Wraps a channel (e.g. the output of processChannel) so that it can be
+// paused. While paused, items are collected in a bounded buffer.
+type FlowControl[T any] struct {
+    mu          sync.Mutex
+    changed     *sync.Cond
+    paused      bool
+    inputClosed bool
+    buffer      []T
+    bufferSize  int
+    policy      OverflowPolicy
+    out         chan T
+}
+
+func NewFlowControl[T any](input <-chan T, bufferSize int, policy OverflowPolicy) *FlowControl[T] {
+    if bufferSize < 1 {
+        panic(fmt.Sprintf("NewFlowControl: bufferSize must be positive, got %d", bufferSize))
+    }
+    f := &FlowControl[T]{
+        buffer:     make([]T, 0, bufferSize),
+        bufferSize: bufferSize,
+        policy:     policy,
+        out:        make(chan T),
+    }
+    f.changed = sync.NewCond(&f.mu)
+    go f.receive(input)
+    go f.emit()
+    return f
+}
+
+func (f *FlowControl[T]) Pause() {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    f.paused = true
+    f.changed.Broadcast()
+}
+
+// Resumes emission. Buffered items are emitted before new ones.
+func (f *FlowControl[T]) Resume() {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    f.paused = false
+    f.changed.Broadcast()
+}
+
+func (f *FlowControl[T]) IsPaused() bool {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    return f.paused
+}
+
+func (f *FlowControl[T]) Channel() <-chan T { return f.out }
+
+// Moves items from input into the buffer
+func (f *FlowControl[T]) receive(input <-chan T) {
+    for item := range input {
+        f.mu.Lock()
+        for len(f.buffer) >= f.bufferSize && !(f.paused && f.policy == OverflowDrop) {
+            f.changed.Wait()
+        }
+        if len(f.buffer) < f.bufferSize {
+            f.buffer = append(f.buffer, item)
+            f.changed.Broadcast()
+        }
+        f.mu.Unlock()
+    }
+
+    f.mu.Lock()
+    f.inputClosed = true
+    f.changed.Broadcast()
+    f.mu.Unlock()
+}
+
+// Moves items from the buffer to the output channel while not paused
+func (f *FlowControl[T]) emit() {
+    defer close(f.out)
+    for {
+        f.mu.Lock()
+        for f.paused || len(f.buffer) == 0 {
+            if len(f.buffer) == 0 && f.inputClosed {
+                f.mu.Unlock()
+                return
+            }
+            f.changed.Wait()
+        }
+        item := f.buffer[0]
+        f.buffer = f.buffer[1:]
+        f.changed.Broadcast()
+        f.mu.Unlock()
+
+        f.out <- item
+    }
+}
+
+
+///////////////////////////////////////////////////////////////
+// Listing 27: Umschalten zwischen zwei Filtern zur Laufzeit //
+///////////////////////////////////////////////////////////////
+
+// Evaluates condition for every item and applies ifTrue or ifFalse accordingly
+func ConditionalProcess[I any](items []I, condition func() bool, ifTrue func(I) bool, ifFalse func(I) bool) []I {
+    return process(items, func(item I) bool {
+        if condition() {
+            return ifTrue(item)
+        }
+        return ifFalse(item)
+    })
+}
+
+// Channel variant of ConditionalProcess, allows switching filters mid-stream
+func ConditionalProcessChannel[I any](items <-chan I, condition func() bool, ifTrue func(I) bool, ifFalse func(I) bool) <-chan I {
+    return processChannel(items, func(item I) bool {
+        if condition() {
+            return ifTrue(item)
+        }
+        return ifFalse(item)
+    })
+}
+
+func main() {
+    items := []sizedLentil{ /*...*/ }
+    /* ... */
+
+    // Be strict during peak load, keep only large lentils then
+    var peakLoad atomic.Bool
+    processedItems := ConditionalProcess(items, peakLoad.Load,
+        func(item sizedLentil) bool { return !item.shouldEat() && item.size() == LARGE },
+        func(item sizedLentil) bool { return !item.shouldEat() })
+    fmt.Println("Eaten:", len(items)-len(processedItems), "Kept:", len(processedItems))
+}
+
+
+///////////////////////////////////////////////////
+// Listing 28: Zwei Aggregate in einem Durchlauf //
+///////////////////////////////////////////////////
+
+// Folds the slice into two independent values in a single pass
+func Reduce2[I, O1, O2 any](items []I, init1 O1, init2 O2, f func(O1, O2, I) (O1, O2)) (O1, O2) {
+    result1, result2 := init1, init2
+    for _, item := range items {
+        result1, result2 = f(result1, result2, item)
+    }
+
+    return result1, result2
+}
+
+func main() {
+    items := []eatOrKeep{ /*...*/ }
+    /* ... */
+
+    // Filter and count eaten items at the same time
+    processedItems, eaten := Reduce2(items, []eatOrKeep{}, 0, func(kept []eatOrKeep, eaten int, item eatOrKeep) ([]eatOrKeep, int) {
+        if !item.shouldEat() {
+            return append(kept, item), eaten
+        }
+        return kept, eaten + 1
+    })
+    fmt.Println("Eaten:", eaten, "Kept:", len(processedItems))
+}
+
+
+///////////////////////////////////////////////
+// Listing 29: Aufteilung in drei Kategorien //
+///////////////////////////////////////////////
+
+// Splits items into three slices in a single pass: items matching first,
+// items matching second (but not first) and all remaining items.
+func Partition3[T any](items []T, first, second func(T) bool) ([]T, []T, []T) {
+    matchFirst, matchSecond, rest := []T{}, []T{}, []T{}
+    for _, item := range items {
+        switch {
+        case first(item):
+            matchFirst = append(matchFirst, item)
+        case second(item):
+            matchSecond = append(matchSecond, item)
+        default:
+            rest = append(rest, item)
+        }
+    }
+
+    return matchFirst, matchSecond, rest
+}
+
+func main() {
+    sizedItems := []sizedLentil{ /*...*/ }
+    /* ... */
+
+    eat, keep, undecided := Partition3(sizedItems,
+        func(item sizedLentil) bool { return item.size() == SMALL },
+        func(item sizedLentil) bool { return item.size() == LARGE })
+    fmt.Println("Eaten:", len(eat), "Kept:", len(keep), "Undecided:", len(undecided))
+}
+
+
+////////////////////////////////////////////////
+// Listing 30: Generische Funktionen für Maps //
+////////////////////////////////////////////////
+
+// Turns every entry of the map into an item of the result slice. Note that
+// the order of the result is undefined as Go does not order map entries.
+func MapEntries[K comparable, V, O any](m map[K]V, f func(K, V) O) []O {
+    result := make([]O, 0, len(m))
+    for key, value := range m {
+        result = append(result, f(key, value))
+    }
+
+    return result
+}
+
+// Returns a new map containing only the entries for which predicate returns
+// true (process for maps)
+func FilterMap[K comparable, V any](m map[K]V, predicate func(K, V) bool) map[K]V {
+    result := make(map[K]V)
+    for key, value := range m {
+        if predicate(key, value) {
+            result[key] = value
+        }
+    }
+
+    return result
+}
+
+// Transforms all values of the map, keys remain unchanged
+func MapMapValues[K comparable, V, O any](m map[K]V, f func(V) O) map[K]O {
+    result := make(map[K]O, len(m))
+    for key, value := range m {
+        result[key] = f(value)
+    }
+
+    return result
+}
+
+
+//////////////////////////////////////////////////
+// Listing 31: Generisches Anfügen und Einfügen //
+//////////////////////////////////////////////////
+
+// Wraps the built-in append so that it can be used as a function value.
+// Note that a variadic function does not match the accumulator type of
+// Reduce, use AppendOne or AppendAll for that.
+func Append[T any](slice []T, items ...T) []T {
+    return append(slice, items...)
+}
+
+// Non-variadic form of Append, e.g. Reduce(items, []T{}, AppendOne[T])
+func AppendOne[T any](slice []T, item T) []T {
+    return append(slice, item)
+}
+
+// Appends a whole slice, e.g. Reduce(sources, []T{}, AppendAll[T]) to join
+// a [][]T
+func AppendAll[T any](slice []T, items []T) []T {
+    return append(slice, items...)
+}
+
+// Inserts items at the front of the slice. Always allocates a new slice.
+func Prepend[T any](slice []T, items ...T) []T {
+    result := make([]T, 0, len(items)+len(slice))
+    result = append(result, items...)
+    return append(result, slice...)
+}
+
+// Inserts items before the given index. Panics if index is not within
+// [0, len(slice)].
+func InsertAt[T any](slice []T, index int, items ...T) []T {
+    if index < 0 || index > len(slice) {
+        panic(fmt.Sprintf("InsertAt: index %d out of range [0, %d]", index, len(slice)))
+    }
+
+    result := make([]T, 0, len(slice)+len(items))
+    result = append(result, slice[:index]...)
+    result = append(result, items...)
+    return append(result, slice[index:]...)
+}
+
+
+////////////////////////////////////////////////////////////
+// Listing 32: Flatten für mehrfach verschachtelte Slices //
+////////////////////////////////////////////////////////////
+
+// Go generics cannot express "slice nested n times", so there is one
+// function per depth. All of them are built on top of Flatten: as Flatten
+// is generic, it also turns [][][]T into [][]T.
+func Flatten2[T any](items [][][]T) []T       { return Flatten(Flatten(items)) }
+func Flatten3[T any](items [][][][]T) []T     { return Flatten2(Flatten(items)) }
+func Flatten4[T any](items [][][][][]T) []T   { return Flatten3(Flatten(items)) }
+func Flatten5[T any](items [][][][][][]T) []T { return Flatten4(Flatten(items)) }
+
+// Reflection-based fallback for arbitrary nesting depth. Like
+// processInterface, it is considerably slower than the generic versions and
+// loses type safety. Flattens depth levels, items must be a slice.
+func FlattenAny(items any, depth int) []any {
+    result := []any{}
+    var flatten func(value reflect.Value, depth int)
+    flatten = func(value reflect.Value, depth int) {
+        for i := 0; i < value.Len(); i++ {
+            item := value.Index(i)
+            if depth > 0 && item.Kind() == reflect.Slice {
+                flatten(item, depth-1)
+            } else {
+                result = append(result, item.Interface())
+            }
+        }
+    }
+
+    flatten(reflect.ValueOf(items), depth)
+    return result
+}
+
+
+////////////////////////////////////
+// Listing 33: Umkehrung von Maps //
+////////////////////////////////////
+
+// Inverts a multimap (e.g. the result of GroupBy): for each value, collects
+// all keys containing it. The order of keys is undefined.
+func InvertMultimap[K comparable, V comparable](m map[K][]V) map[V][]K {
+    result := make(map[V][]K)
+    for key, values := range m {
+        for _, value := range values {
+            result[value] = append(result[value], key)
+        }
+    }
+
+    return result
+}
+
+// Inverts a map by collecting all keys that map to the same value. The
+// order of keys is undefined.
+func TransposeMap[K, V comparable](m map[K]V) map[V][]K {
+    result := make(map[V][]K)
+    for key, value := range m {
+        result[value] = append(result[value], key)
+    }
+
+    return result
+}
+
+
+/////////////////////////////////////////////////////
+// Listing 34: Variadische Min- und Max-Funktionen //
+/////////////////////////////////////////////////////
+
+// Returns the largest of the given values. As at least one value is
+// required, there is no need to handle the empty case.
+func Max[T constraints.Ordered](first T, rest ...T) T {
+    result := first
+    for _, value := range rest {
+        if value > result {
+            result = value
+        }
+    }
+
+    return result
+}
+
+// Returns the smallest of the given values
+func Min[T constraints.Ordered](first T, rest ...T) T {
+    result := first
+    for _, value := range rest {
+        if value < result {
+            result = value
+        }
+    }
+
+    return result
+}
+
+
+///////////////////////////////////////////////////
+// Listing 35: Suche in zweidimensionalen Slices //
+///////////////////////////////////////////////////
+
+// Returns true if any of the sub-slices contains target
+func Contains2D[T comparable](matrix [][]T, target T) bool {
+    _, _, found := IndexOf2D(matrix, target)
+    return found
+}
+
+// Returns the position of the first occurrence of target
+func IndexOf2D[T comparable](matrix [][]T, target T) (row, col int, found bool) {
+    for row, items := range matrix {
+        for col, item := range items {
+            if item == target {
+                return row, col, true
+            }
+        }
+    }
+
+    return -1, -1, false
+}
+
+
+//////////////////////////////////////
+// Listing 36: Dünn besetzte Slices //
+//////////////////////////////////////
+
+// Stores only some items of a slice together with their original index.
+// Use NewSparse or NewSparseFrom to create it.
+type Sparse[T any] struct {
+    items map[int]T
+}
+
+func NewSparse[T any]() Sparse[T] {
+    return Sparse[T]{items: make(map[int]T)}
+}
+
+// Keeps only the items for which predicate returns true (like process),
+// but remembers their index in the original slice.
+func NewSparseFrom[T any](items []T, predicate func(T) bool) Sparse[T] {
+    result := NewSparse[T]()
+    for index, item := range items {
+        if predicate(item) {
+            result.Set(index, item)
+        }
+    }
+
+    return result
+}
+
+func (s Sparse[T]) Set(index int, item T) { s.items[index] = item }
+
+func (s Sparse[T]) Get(index int) (T, bool) {
+    item, ok := s.items[index]
+    return item, ok
+}
+
+// Returns the indices of all stored items in ascending order
+func (s Sparse[T]) Indices() []int {
+    result := make([]int, 0, len(s.items))
+    for index := range s.items {
+        result = append(result, index)
+    }
+    sort.Ints(result)
+
+    return result
+}
+
+// Returns the stored items ordered by their index
+func (s Sparse[T]) Values() []T {
+    result := make([]T, 0, len(s.items))
+    for _, index := range s.Indices() {
+        result = append(result, s.items[index])
+    }
+
+    return result
+}
+
+// Materializes a slice with the given length. Absent indices are filled
+// with zero, items with an index beyond length are ignored.
+func (s Sparse[T]) DenseSlice(length int, zero T) []T {
+    result := make([]T, length)
+    for index := range result {
+        if item, ok := s.items[index]; ok {
+            result[index] = item
+        } else {
+            result[index] = zero
+        }
+    }
+
+    return result
+}
+
+
+//////////////////////////////////////////
+// Listing 37: Entfernen von Nullwerten //
+//////////////////////////////////////////
+
+// Removes all items that equal the zero value of T
+func Compact[T comparable](items []T) []T {
+    var zero T
+    return CompactBy(items, func(item T) bool { return item == zero })
+}
+
+// Like Compact, but for types that are not comparable
+func CompactBy[T any](items []T, isZero func(T) bool) []T {
+    result := make([]T, 0, len(items))
+    for _, item := range items {
+        if !isZero(item) {
+            result = append(result, item)
+        }
+    }
+
+    return result
+}
+
+// Combines process and Compact, result is only allocated once
+func processAndCompact[T comparable](items []T, filter func(T) bool) []T {
+    var zero T
+    result := make([]T, 0, len(items))
+    for _, item := range items {
+        if item != zero && filter(item) {
+            result = append(result, item)
+        }
+    }
+
+    return result
+}
+
+
+///////////////////////////////////////
+// Listing 38: Sichere Typumwandlung //
+///////////////////////////////////////
+
+// Checked type assertion, returns false instead of panicking
+func SafeCast[O any](v any) (O, bool) {
+    result, ok := v.(O)
+    return result, ok
+}
+
+// Returns fallback if v is not of type O
+func SafeCastOr[O any](v any, fallback O) O {
+    if result, ok := v.(O); ok {
+        return result
+    }
+    return fallback
+}
+
+// Panics with a message containing source and target type if v is not of type O
+func MustCast[O any](v any) O {
+    result, ok := v.(O)
+    if !ok {
+        panic(fmt.Sprintf("MustCast: cannot cast %T to %s", v, reflect.TypeOf((*O)(nil)).Elem()))
+    }
+    return result
+}
+
+func main() {
+    items := []eatOrKeep{ /*...*/ }
+    /* ... */
+    interfacedItems := processInterface(items, func(item eatOrKeep) bool { return !item.shouldEat() })
+    if processedItems, ok := SafeCast[[]eatOrKeep](interfacedItems); ok {
+        fmt.Println("Eaten:", len(items)-len(processedItems), "Kept:", len(processedItems))
+    }
+}
+
+
+///////////////////////////////////////////////////
+// Listing 39: Beobachtung von Channel-Pipelines //
+///////////////////////////////////////////////////
+
+// Number of items observe may lag behind the main flow in Observe
+const observeSlack = 64
+
+// Passes a copy of every item to observe (e.g. to collect statistics) and
+// forwards the items unchanged. The returned future (see listing 74)
+// resolves as soon as observe returns. If observe returns before its
+// channel is closed, it simply does not receive further items. observe
+// may fall behind by up to observeSlack items, after that the main flow
+// waits for it, so observe should not block for long.
+func Observe[T, R any](input <-chan T, observe func(<-chan T) R) (<-chan T, *Future[R]) {
+    out := make(chan T)
+    observed := make(chan T, observeSlack)
+    result := NewFuture(func() (R, error) { return observe(observed), nil })
+    go func() {
+        defer close(out)
+        defer close(observed)
+        for item := range input {
+            select {
+            case observed <- item:
+            case <-result.done:
+            }
+            out <- item
+        }
+    }()
+    return out, result
+}
+
+func main() {
+    items := []eatOrKeep{ /*...*/ }
+    /* ... */
+
+    in := make(chan eatOrKeep, len(items))
+    for _, item := range items {
+        in <- item
+    }
+    close(in)
+
+    // Count lentils without modifying the pipeline
+    observed, lentilCount := Observe(in, func(items <-chan eatOrKeep) int {
+        count := 0
+        for item := range items {
+            if _, ok := item.(lentil); ok {
+                count++
+            }
+        }
+        return count
+    })
+    remaining := 0
+    for range processChannel(observed, func(item eatOrKeep) bool { return !item.shouldEat() }) {
+        remaining++
+    }
+    lentils, _ := lentilCount.Get()
+    fmt.Println("Lentils:", lentils, "Kept:", remaining)
+}
+
+
+///////////////////////////////////////////////
+// Listing 40: Sammeln von Ablehnungsgründen //
+///////////////////////////////////////////////
+
+// Used as reason if a filter rejects an item without providing an error
+var ErrRejected = errors.New("rejected by filter")
+
+// Rejected item together with the reason for rejecting it
+type ItemError[T any] struct {
+    Item   T
+    Reason error
+}
+
+func (e ItemError[T]) Error() string {
+    return fmt.Sprintf("%v: %v", e.Item, e.reason())
+}
+
+// A zero ItemError has no reason, it counts as a plain rejection then
+func (e ItemError[T]) reason() error {
+    if e.Reason == nil {
+        return ErrRejected
+    }
+    return e.Reason
+}
+
+// Errors cannot be serialized to JSON directly, so we use the message
+func (e ItemError[T]) MarshalJSON() ([]byte, error) {
+    return json.Marshal(struct {
+        Item   T      `json:"item"`
+        Reason string `json:"reason"`
+    }{e.Item, e.reason().Error()})
+}
+
+// Collects rejected items in the order they have been added
+type TypedErrors[T any] struct {
+    errors []ItemError[T]
+}
+
+func (t *TypedErrors[T]) Add(item T, reason error) {
+    if reason == nil {
+        reason = ErrRejected
+    }
+    t.errors = append(t.errors, ItemError[T]{Item: item, Reason: reason})
+}
+
+func (t *TypedErrors[T]) Errors() []ItemError[T] { return t.errors }
+
+func (t *TypedErrors[T]) HasErrors() bool { return len(t.errors) > 0 }
+
+// Returns all rejected items
+func (t *TypedErrors[T]) Items() []T {
+    result := make([]T, 0, len(t.errors))
+    for _, e := range t.errors {
+        result = append(result, e.Item)
+    }
+
+    return result
+}
+
+// Serialized as array of items and reasons, e.g. for an audit trail
+func (t *TypedErrors[T]) MarshalJSON() ([]byte, error) {
+    if t.errors == nil {
+        return []byte("[]"), nil
+    }
+    return json.Marshal(t.errors)
+}
+
+// Like process, but the filter can explain why it rejects an item. If the
+// filter returns an error, the item is rejected regardless of the boolean.
+func ProcessWithErrors[I any](items []I, filter func(I) (bool, error)) ([]I, *TypedErrors[I]) {
+    result := []I{}
+    rejected := &TypedErrors[I]{}
+    for _, item := range items {
+        keep, err := filter(item)
+        switch {
+        case err != nil:
+            rejected.Add(item, err)
+        case !keep:
+            rejected.Add(item, ErrRejected)
+        default:
+            result = append(result, item)
+        }
+    }
+
+    return result, rejected
+}
+
+
+//////////////////////////////////////////////////////////
+// Listing 41: Aneinanderhängen von Slices und Channels //
+//////////////////////////////////////////////////////////
+
+// Joins all slices in order. This is the recommended way to combine the
+// results of multiple process calls over different input slices.
+func Concat[T any](slices ...[]T) []T {
+    total := 0
+    for _, slice := range slices {
+        total += len(slice)
+    }
+
+    result := make([]T, 0, total)
+    for _, slice := range slices {
+        result = append(result, slice...)
+    }
+
+    return result
+}
+
+// Forwards all items of the first channel, then all items of the second
+// one, and so on. In contrast to merging channels, the order is preserved.
+func ConcatChannels[T any](channels ...<-chan T) <-chan T {
+    out := make(chan T)
+    go func() {
+        defer close(out)
+        for _, channel := range channels {
+            for item := range channel {
+                out <- item
+            }
+        }
+    }()
+    return out
+}
+
+func main() {
+    lentils := []eatOrKeep{ /*...*/ }
+    snails := []eatOrKeep{ /*...*/ }
+    /* ... */
+
+    keep := func(item eatOrKeep) bool { return !item.shouldEat() }
+    processedItems := Concat(process(lentils, keep), process(snails, keep))
+    fmt.Println("Kept:", len(processedItems))
+}
+
+
+///////////////////////////////////////////////////
+// Listing 42: Zufällige Reihenfolge in Channels //
+///////////////////////////////////////////////////
+
+// Collects up to bufferSize items and emits them in random order before
+// reading more items. Useful to break ordering biases of the input, e.g.
+// before benchmarking bubblesort. Remaining items are shuffled and emitted
+// when input is closed. If rng is nil, a time-seeded one is used.
+func ShuffleBuffer[T any](input <-chan T, bufferSize int, rng *rand.Rand) <-chan T {
+    if bufferSize < 1 {
+        panic(fmt.Sprintf("ShuffleBuffer: bufferSize must be positive, got %d", bufferSize))
+    }
+    rng = randOrDefault(rng)
+    out := make(chan T)
+    go func() {
+        defer close(out)
+        buffer := make([]T, 0, bufferSize)
+        flush := func() {
+            Shuffle(buffer, rng)
+            for _, item := range buffer {
+                out <- item
+            }
+            buffer = buffer[:0]
+        }
+
+        for item := range input {
+            buffer = append(buffer, item)
+            if len(buffer) == bufferSize {
+                flush()
+            }
+        }
+        flush()
+    }()
+    return out
+}
+
+
+///////////////////////////////////////////////////
+// Listing 43: Zwischenspeichern von Ergebnissen //
+///////////////////////////////////////////////////
+
+// Storage used by CacheAside. Any cache implementation (e.g. an LRU cache)
+// with these methods can be plugged in.
+type CacheBackend[K comparable, V any] interface {
+    Get(key K) (V, bool)
+    Put(key K, value V)
+}
+
+// Unbounded backend based on a map, safe for concurrent use
+type mapBackend[K comparable, V any] struct {
+    mu    sync.RWMutex
+    items map[K]V
+}
+
+func NewMapBackend[K comparable, V any]() CacheBackend[K, V] {
+    return &mapBackend[K, V]{items: make(map[K]V)}
+}
+
+func (b *mapBackend[K, V]) Get(key K) (V, bool) {
+    b.mu.RLock()
+    defer b.mu.RUnlock()
+    value, ok := b.items[key]
+    return value, ok
+}
+
+func (b *mapBackend[K, V]) Put(key K, value V) {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    b.items[key] = value
+}
+
+// Implements the cache-aside pattern on top of a pluggable backend
+type CacheAside[K comparable, V any] struct {
+    backend CacheBackend[K, V]
+}
+
+func NewCacheAside[K comparable, V any](backend CacheBackend[K, V]) CacheAside[K, V] {
+    return CacheAside[K, V]{backend: backend}
+}
+
+// Returns the cached value for key. If there is none, load is called and
+// its result is cached. Errors are not cached.
+func (c CacheAside[K, V]) GetOrLoad(key K, load func() (V, error)) (V, error) {
+    if value, ok := c.backend.Get(key); ok {
+        return value, nil
+    }
+
+    value, err := load()
+    if err != nil {
+        return value, err
+    }
+    c.backend.Put(key, value)
+    return value, nil
+}
+
+// Like process, but the result is cached under the key calculated by
+// cacheKey. The caller must make sure that the key covers the filter, too.
+func ProcessCached[I any, K comparable](items []I, cacheKey func([]I) K, filter func(I) bool, cache CacheAside[K, []I]) []I {
+    result, _ := cache.GetOrLoad(cacheKey(items), func() ([]I, error) {
+        return process(items, filter), nil
+    })
+    return result
+}
+
+
+///////////////////////////////////////////////////////////////
+// Listing 44: Vergleich von Implementierungen per Benchmark //
+///////////////////////////////////////////////////////////////
+
+// Returns true if both slices have the same length and eq returns true for
+// all pairs of items
+func EqualBy[T any](a, b []T, eq func(T, T) bool) bool {
+    if len(a) != len(b) {
+        return false
+    }
+    for i := range a {
+        if !eq(a[i], b[i]) {
+            return false
+        }
+    }
+
+    return true
+}
+
+// Runs each implementation as a sub-benchmark of b. Before measuring, all
+// implementations must return the same result, otherwise the benchmark fails.
+func CompareBenchmarks[I any](name string, items []I, filter func(I) bool, implementations map[string]func([]I, func(I) bool) []I, b *testing.B) {
+    // Sort names so that sub-benchmarks always run in the same order
+    names := make([]string, 0, len(implementations))
+    for implName := range implementations {
+        names = append(names, implName)
+    }
+    sort.Strings(names)
+
+    var expected []I
+    for i, implName := range names {
+        result := implementations[implName](items, filter)
+        if i == 0 {
+            expected = result
+        } else if !EqualBy(expected, result, func(lhs, rhs I) bool { return reflect.DeepEqual(lhs, rhs) }) {
+            b.Fatalf("%s: %s returns different result than %s", name, implName, names[0])
+        }
+    }
+
+    for _, implName := range names {
+        impl := implementations[implName]
+        b.Run(name+"/"+implName, func(b *testing.B) {
+            for i := 0; i < b.N; i++ {
+                impl(items, filter)
+            }
+        })
+    }
+}
+
+func BenchmarkProcess(b *testing.B) {
+    items := []eatOrKeep{ /*...*/ }
+    /* ... */
+
+    CompareBenchmarks("eatOrKeep", items, func(item eatOrKeep) bool { return !item.shouldEat() },
+        map[string]func([]eatOrKeep, func(eatOrKeep) bool) []eatOrKeep{
+            "generic": process[eatOrKeep],
+            "reflection": func(items []eatOrKeep, filter func(eatOrKeep) bool) []eatOrKeep {
+                return processInterface(items, filter).([]eatOrKeep)
+            },
+        }, b)
+}
+
+
+//////////////////////////////////////////////////////////////////
+// Listing 45: Zwischenergebnisse einer Aggregation als Channel //
+//////////////////////////////////////////////////////////////////
+
+// Folds the items of input and emits the accumulator after each item
+func StepReduce[I, O any](input <-chan I, initial O, accumulate func(O, I) O) <-chan O {
+    out := make(chan O)
+    go func() {
+        defer close(out)
+        result := initial
+        for item := range input {
+            result = accumulate(result, item)
+            out <- result
+        }
+    }()
+    return out
+}
+
+// Only forwards items that differ from the previous one. Combined with
+// StepReduce, it emits an aggregate only when it has actually changed.
+func WhenChanged[T comparable](input <-chan T) <-chan T {
+    out := make(chan T)
+    go func() {
+        defer close(out)
+        var previous T
+        first := true
+        for item := range input {
+            if first || item != previous {
+                out <- item
+            }
+            previous, first = item, false
+        }
+    }()
+    return out
+}
+
+func main() {
+    in := make(chan eatOrKeep, 4)
+    in <- lentil{isGood: true}
+    in <- lentil{isGood: false}
+    in <- lentil{isGood: false}
+    in <- lentil{isGood: true}
+    close(in)
+
+    // Monitor the number of eaten items
+    eaten := StepReduce(in, 0, func(eaten int, item eatOrKeep) int {
+        if item.shouldEat() {
+            return eaten + 1
+        }
+        return eaten
+    })
+    for count := range WhenChanged(eaten) {
+        fmt.Println("Eaten so far:", count)
+    }
+}
+
+
+/////////////////////////////////////////
+// Listing 46: Generische Map-Funktion //
+/////////////////////////////////////////
+
+// Transforms each item. Note that the type of the result can differ from
+// the type of the input, so we need a second type parameter.
+func Map[I, O any](items []I, transform func(I) O) []O {
+    result := make([]O, len(items))
+    for index, item := range items {
+        result[index] = transform(item)
+    }
+
+    return result
+}
+
+func main() {
+    sizedItems := []sizedLentil{ /*...*/ }
+    /* ... */
+
+    sizes := Map(sizedItems, func(item sizedLentil) int { return item.size() })
+    fmt.Println("Sizes:", sizes)
+}
+
+
+////////////////////////////////////////////
+// Listing 47: Generische Reduce-Funktion //
+////////////////////////////////////////////
+
+// Folds all items into a single value. accumulator receives the result so
+// far and the current item. Returns initial for an empty slice.
+func Reduce[I, O any](items []I, initial O, accumulator func(O, I) O) O {
+    result := initial
+    for _, item := range items {
+        result = accumulator(result, item)
+    }
+
+    return result
+}
+
+func main() {
+    sizedItems := []sizedLentil{ /*...*/ }
+    /* ... */
+
+    totalSize := Reduce(sizedItems, 0, func(total int, item sizedLentil) int { return total + item.size() })
+    fmt.Println("Total size:", totalSize)
+}
+
+
+/////////////////////////////////////////////
+// Listing 48: Generische FlatMap-Funktion //
+/////////////////////////////////////////////
+
+// Transforms each item into zero or more items of a different type
+func FlatMap[I, O any](items []I, transform func(I) []O) []O {
+    result := make([]O, 0, len(items))
+    for _, item := range items {
+        result = append(result, transform(item)...)
+    }
+
+    return result
+}
+
+func main() {
+    genericBag := newGenericItemsBag(func(lhs, rhs sizedLentil) bool { return lhs.size() == rhs.size() })
+    /* ... */
+
+    // Expand each group of the bag into individual sizes
+    sizes := FlatMap(genericBag.bag, func(group genericItemsGroup[sizedLentil]) []int {
+        result := make([]int, group.count)
+        for i := range result {
+            result[i] = group.item.size()
+        }
+        return result
+    })
+    fmt.Println("Sizes:", sizes)
+}
+
+
+///////////////////////////////////////////////////////////////
+// Listing 49: Aufteilen eines Slices anhand eines Prädikats //
+///////////////////////////////////////////////////////////////
+
+// Splits items in a single pass. The first slice contains the items for
+// which predicate returns true, the second one all others. Both keep the
+// original order.
+func Partition[I any](items []I, predicate func(I) bool) ([]I, []I) {
+    matching, rest := []I{}, []I{}
+    for _, item := range items {
+        if predicate(item) {
+            matching = append(matching, item)
+        } else {
+            rest = append(rest, item)
+        }
+    }
+
+    return matching, rest
+}
+
+func main() {
+    items := []eatOrKeep{ /*...*/ }
+    /* ... */
+
+    eaten, kept := Partition(items, func(item eatOrKeep) bool { return item.shouldEat() })
+    fmt.Println("Eaten:", len(eaten), "Kept:", len(kept))
+}
+
+
+////////////////////////////////////////
+// Listing 50: Generische Tupel-Typen //
+////////////////////////////////////////
+
+// Go has no built-in tuples, so we define generic ones
+type Pair[A, B any] struct {
+    First  A
+    Second B
+}
+
+func (p Pair[A, B]) String() string {
+    return fmt.Sprintf("(%v, %v)", p.First, p.Second)
+}
+
+// Returns a new pair with the elements exchanged. Note that the type
+// parameters are swapped, too.
+func (p Pair[A, B]) Swap() Pair[B, A] {
+    return Pair[B, A]{First: p.Second, Second: p.First}
+}
+
+type Triple[A, B, C any] struct {
+    First  A
+    Second B
+    Third  C
+}
+
+func (t Triple[A, B, C]) String() string {
+    return fmt.Sprintf("(%v, %v, %v)", t.First, t.Second, t.Third)
+}
+
+
+///////////////////////////////
+// Listing 51: Zip und Unzip //
+///////////////////////////////
+
+// Combines items with the same index into pairs. If the slices differ in
+// length, surplus items of the longer one are ignored.
+func Zip[A, B any](a []A, b []B) []Pair[A, B] {
+    length := len(a)
+    if len(b) < length {
+        length = len(b)
+    }
+
+    result := make([]Pair[A, B], length)
+    for i := range result {
+        result[i] = Pair[A, B]{First: a[i], Second: b[i]}
+    }
+
+    return result
+}
+
+// Inverse of Zip
+func Unzip[A, B any](pairs []Pair[A, B]) ([]A, []B) {
+    first, second := make([]A, len(pairs)), make([]B, len(pairs))
+    for i, pair := range pairs {
+        first[i], second[i] = pair.First, pair.Second
+    }
+
+    return first, second
+}
+
+func main() {
+    items := []eatOrKeep{ /*...*/ }
+    timestamps := []time.Time{ /*...*/ }
+    /* ... */
+
+    for _, processed := range Zip(items, timestamps) {
+        fmt.Println("Should eat:", processed.First.shouldEat(), "Processed at:", processed.Second)
+    }
+}
+
+
+///////////////////////////////////////////
+// Listing 52: Gruppieren nach Schlüssel //
+///////////////////////////////////////////
+
+// Groups items by the key calculated by keyFn. In contrast to
+// genericItemsBag, items do not need to be consecutive to end up in the
+// same group. Items within a group keep their original order.
+func GroupBy[I any, K comparable](items []I, keyFn func(I) K) map[K][]I {
+    result := make(map[K][]I)
+    for _, item := range items {
+        key := keyFn(item)
+        result[key] = append(result[key], item)
+    }
+
+    return result
+}
+
+// Like GroupBy, but returns the groups in a slice ordered by the first
+// occurrence of their key. Use it if you need deterministic output.
+func GroupBySlice[I any, K comparable](items []I, keyFn func(I) K) []Pair[K, []I] {
+    result := []Pair[K, []I]{}
+    positions := make(map[K]int)
+    for _, item := range items {
+        key := keyFn(item)
+        position, ok := positions[key]
+        if !ok {
+            position = len(result)
+            positions[key] = position
+            result = append(result, Pair[K, []I]{First: key})
+        }
+        result[position].Second = append(result[position].Second, item)
+    }
+
+    return result
+}
+
+func main() {
+    items := []eatOrKeep{ /*...*/ }
+    /* ... */
+
+    groups := GroupBy(items, func(item eatOrKeep) bool { return item.shouldEat() })
+    fmt.Println("Eaten:", len(groups[true]), "Kept:", len(groups[false]))
+}
+
+
+//////////////////////////////////////////
+// Listing 53: Entfernen von Duplikaten //
+//////////////////////////////////////////
+
+// Returns the first occurrence of each item, preserving order
+func Distinct[I comparable](items []I) []I {
+    return DistinctBy(items, func(item I) I { return item })
+}
+
+// Like Distinct, but two items are considered equal if keyFn returns the
+// same key. Allows deduplication of types that are not comparable.
+func DistinctBy[I any, K comparable](items []I, keyFn func(I) K) []I {
+    result := []I{}
+    seen := make(map[K]struct{})
+    for _, item := range items {
+        key := keyFn(item)
+        if _, ok := seen[key]; !ok {
+            seen[key] = struct{}{}
+            result = append(result, item)
+        }
+    }
+
+    return result
+}
+
+
+/////////////////////////////////////////////////////
+// Listing 54: Take, Drop, TakeWhile und DropWhile //
+/////////////////////////////////////////////////////
+
+// Clamps n to the range [0, len]
+func clampLength(n, length int) int {
+    if n < 0 {
+        return 0
+    }
+    if n > length {
+        return length
+    }
+    return n
+}
+
+// Returns a copy of the first n items (or all if there are fewer)
+func Take[I any](items []I, n int) []I {
+    return append([]I{}, items[:clampLength(n, len(items))]...)
+}
+
+// Returns a copy of all items except the first n
+func Drop[I any](items []I, n int) []I {
+    return append([]I{}, items[clampLength(n, len(items)):]...)
+}
+
+// Returns items up to (not including) the first one failing predicate
+func TakeWhile[I any](items []I, predicate func(I) bool) []I {
+    return Take(items, countWhile(items, predicate))
+}
+
+// Skips items up to (not including) the first one failing predicate
+func DropWhile[I any](items []I, predicate func(I) bool) []I {
+    return Drop(items, countWhile(items, predicate))
+}
+
+// Number of leading items satisfying predicate
+func countWhile[I any](items []I, predicate func(I) bool) int {
+    for index, item := range items {
+        if !predicate(item) {
+            return index
+        }
+    }
+    return len(items)
+}
+
+
+/////////////////////////////////////////////////
+// Listing 55: Aufteilen in gleich große Teile //
+/////////////////////////////////////////////////
+
+// Splits items into chunks of the given size, the last chunk may be
+// shorter. Chunks are copies, modifying them does not affect items.
+func Chunk[I any](items []I, size int) [][]I {
+    if size <= 0 {
+        panic(fmt.Sprintf("Chunk: size must be positive, got %d", size))
+    }
+
+    result := make([][]I, 0, (len(items)+size-1)/size)
+    for start := 0; start < len(items); start += size {
+        end := start + size
+        if end > len(items) {
+            end = len(items)
+        }
+        result = append(result, append([]I{}, items[start:end]...))
+    }
+
+    return result
+}
+
+func main() {
+    genericBag := newGenericItemsBag(func(lhs eatOrKeep, rhs eatOrKeep) bool { return lhs.shouldEat() == rhs.shouldEat() })
+    /* ... */
+
+    for page, items := range Chunk(genericBag.getItems(), 10) {
+        fmt.Println("Page:", page, "Items:", len(items))
+    }
+}
+
+
+///////////////////////////////////////////////////////
+// Listing 56: Zusammenfassen verschachtelter Slices //
+///////////////////////////////////////////////////////
+
+// Concatenates all inner slices in order. Counterpart to Chunk. The result
+// is a new slice, even if items is empty.
+func Flatten[I any](items [][]I) []I {
+    total := 0
+    for _, inner := range items {
+        total += len(inner)
+    }
+
+    result := make([]I, 0, total)
+    for _, inner := range items {
+        result = append(result, inner...)
+    }
+
+    return result
+}
+
+
+///////////////////////////////////
+// Listing 57: Any, All und None //
+///////////////////////////////////
+
+// Returns true as soon as one item satisfies predicate
+func Any[I any](items []I, predicate func(I) bool) bool {
+    for _, item := range items {
+        if predicate(item) {
+            return true
+        }
+    }
+    return false
+}
+
+// Returns false as soon as one item does not satisfy predicate
+func All[I any](items []I, predicate func(I) bool) bool {
+    for _, item := range items {
+        if !predicate(item) {
+            return false
+        }
+    }
+    return true
+}
+
+// Returns false as soon as one item satisfies predicate
+func None[I any](items []I, predicate func(I) bool) bool {
+    return !Any(items, predicate)
+}
+
+func main() {
+    items := []eatOrKeep{ /*...*/ }
+    /* ... */
+
+    // No need to call process and check the length of the result
+    if None(items, func(item eatOrKeep) bool { return item.shouldEat() }) {
+        fmt.Println("Bird stays hungry")
+    }
+}
+
+
+/////////////////////////////////////////////////////////
+// Listing 58: Suche nach dem ersten passenden Element //
+/////////////////////////////////////////////////////////
+
+// Returns the first item satisfying predicate. If there is none, the zero
+// value of I and false is returned.
+func Find[I any](items []I, predicate func(I) bool) (I, bool) {
+    if index := FindIndex(items, predicate); index >= 0 {
+        return items[index], true
+    }
+
+    var zero I
+    return zero, false
+}
+
+// Returns the index of the first item satisfying predicate or -1
+func FindIndex[I any](items []I, predicate func(I) bool) int {
+    for index, item := range items {
+        if predicate(item) {
+            return index
+        }
+    }
+    return -1
+}
+
+
+///////////////////////////////////////////////
+// Listing 59: Suche nach gleichen Elementen //
+///////////////////////////////////////////////
+
+// Returns true if items contains target. Thanks to comparable, equality is
+// checked at compile time instead of using reflect.DeepEqual.
+func Contains[I comparable](items []I, target I) bool {
+    return IndexOf(items, target) >= 0
+}
+
+// Returns the index of the first item equal to target or -1
+func IndexOf[I comparable](items []I, target I) int {
+    return FindIndex(items, func(item I) bool { return item == target })
+}
+
+
+///////////////////////////////////////
+// Listing 60: Umkehren und Rotieren //
+///////////////////////////////////////
+
+// Returns a reversed copy, items remain unchanged
+func Reverse[I any](items []I) []I {
+    result := make([]I, len(items))
+    for index, item := range items {
+        result[len(items)-1-index] = item
+    }
+
+    return result
+}
+
+// Returns a copy rotated left by n positions. Negative n rotates right.
+func Rotate[I any](items []I, n int) []I {
+    if len(items) == 0 {
+        return []I{}
+    }
+
+    // Normalize n to [0, len), works for negative n and n > len, too
+    n = ((n % len(items)) + len(items)) % len(items)
+    return append(append(make([]I, 0, len(items)), items[n:]...), items[:n]...)
+}
+
+func main() {
+    sizedItems := []sizedEatOrKeep{
+        sizedLentil{lentilSize: LARGE, lentil: lentil{isGood: true}},
+        sizedLentil{lentilSize: MEDIUM, lentil: lentil{isGood: false}},
+        sizedLentil{lentilSize: SMALL, lentil: lentil{isGood: true}},
+    }
+    processedOrderd := processAndSort(sizedItems, func(item sizedEatOrKeep) bool { return !item.shouldEat() })
+    for _, sortedItem := range Reverse(processedOrderd) {
+        fmt.Println("Size:", sortedItem.size())
+    }
+}
+
+
+////////////////////////////////////////////////
+// Listing 61: Minimum und Maximum von Slices //
+////////////////////////////////////////////////
+
+// Min and Max already exist for variadic arguments (see listing 34), so the
+// slice variants need different names.
+
+// Returns the smallest item and false if items is empty
+func MinSlice[I constraints.Ordered](items []I) (I, bool) {
+    return MinBy(items, func(item I) I { return item })
+}
+
+// Returns the largest item and false if items is empty
+func MaxSlice[I constraints.Ordered](items []I) (I, bool) {
+    return MaxBy(items, func(item I) I { return item })
+}
+
+// Returns the item with the smallest key. Like in bubblesort, keyFn turns
+// each item into a type compatible with Ordered.
+func MinBy[I any, O constraints.Ordered](items []I, keyFn func(I) O) (I, bool) {
+    return extremeBy(items, keyFn, func(candidate, current O) bool { return candidate < current })
+}
+
+// Returns the item with the largest key
+func MaxBy[I any, O constraints.Ordered](items []I, keyFn func(I) O) (I, bool) {
+    return extremeBy(items, keyFn, func(candidate, current O) bool { return candidate > current })
+}
+
+func extremeBy[I any, O constraints.Ordered](items []I, keyFn func(I) O, better func(candidate, current O) bool) (I, bool) {
+    if len(items) == 0 {
+        var zero I
+        return zero, false
+    }
+    if len(items) == 1 {
+        return items[0], true
+    }
+
+    result, resultKey := items[0], keyFn(items[0])
+    for _, item := range items[1:] {
+        if key := keyFn(item); better(key, resultKey) {
+            result, resultKey = item, key
+        }
+    }
+
+    return result, true
+}
+
+func main() {
+    sizedItems := []sizedLentil{ /*...*/ }
+    /* ... */
+
+    if largest, ok := MaxBy(sizedItems, func(item sizedLentil) int { return item.size() }); ok {
+        fmt.Println("Largest:", largest.size())
+    }
+}
+
+
+//////////////////////////////////////////
+// Listing 62: Numerische Aggregationen //
+//////////////////////////////////////////
+
+// Number is a constraint that permits any integer or floating-point type
+type Number interface {
+    Integer | Float
+}
+
+func Sum[I Number](items []I) I {
+    var result I
+    for _, item := range items {
+        result += item
+    }
+    return result
+}
+
+// Accumulates in float64 to avoid integer truncation. Returns NaN for an
+// empty slice.
+func Average[I Number](items []I) float64 {
+    if len(items) == 0 {
+        return math.NaN()
+    }
+
+    total := 0.0
+    for _, item := range items {
+        total += float64(item)
+    }
+    return total / float64(len(items))
+}
+
+// Returns 1 for an empty slice
+func Product[I Number](items []I) I {
+    var result I = 1
+    for _, item := range items {
+        result *= item
+    }
+    return result
+}
+
+func main() {
+    sizedItems := []sizedLentil{ /*...*/ }
+    /* ... */
+
+    sizes := Map(sizedItems, func(item sizedLentil) int { return item.size() })
+    fmt.Println("Total:", Sum(sizes), "Average:", Average(sizes))
+}
+
+
+///////////////////////////////////
+// Listing 63: Generischer Stack //
+///////////////////////////////////
+
+// Last-in-first-out collection. The zero value is an empty stack.
+type Stack[T any] struct {
+    items []T
+}
+
+func (s *Stack[T]) Push(item T) {
+    s.items = append(s.items, item)
+}
+
+// Removes and returns the top item. Returns false if the stack is empty.
+func (s *Stack[T]) Pop() (T, bool) {
+    item, ok := s.Peek()
+    if ok {
+        // Clear the slot so that the garbage collector can free the item
+        var zero T
+        s.items[len(s.items)-1] = zero
+        s.items = s.items[:len(s.items)-1]
+    }
+    return item, ok
+}
+
+// Returns the top item without removing it
+func (s *Stack[T]) Peek() (T, bool) {
+    if len(s.items) == 0 {
+        var zero T
+        return zero, false
+    }
+    return s.items[len(s.items)-1], true
+}
+
+func (s *Stack[T]) IsEmpty() bool { return len(s.items) == 0 }
+
+func (s *Stack[T]) Len() int { return len(s.items) }
+
+// Returns a copy of all items from bottom to top
+func (s *Stack[T]) ToSlice() []T {
+    return append([]T{}, s.items...)
+}
+
+
+////////////////////////////////////////////
+// Listing 64: Generische Queue und Deque //
+////////////////////////////////////////////
+
+// First-in-first-out collection backed by a circular buffer that grows
+// when it is full. The zero value is an empty queue.
+type Queue[T any] struct {
+    ring  []T
+    head  int // Index of the first item in ring
+    count int
+}
+
+func (q *Queue[T]) Enqueue(item T) {
+    q.grow()
+    q.ring[(q.head+q.count)%len(q.ring)] = item
+    q.count++
+}
+
+// Removes and returns the first item. Returns false if the queue is empty.
+func (q *Queue[T]) Dequeue() (T, bool) {
+    item, ok := q.Peek()
+    if ok {
+        var zero T
+        q.ring[q.head] = zero
+        q.head = (q.head + 1) % len(q.ring)
+        q.count--
+    }
+    return item, ok
+}
+
+// Returns the first item without removing it
+func (q *Queue[T]) Peek() (T, bool) {
+    if q.count == 0 {
+        var zero T
+        return zero, false
+    }
+    return q.ring[q.head], true
+}
+
+func (q *Queue[T]) IsEmpty() bool { return q.count == 0 }
+
+func (q *Queue[T]) Len() int { return q.count }
+
+// Doubles the capacity of the ring if it is full. Items are copied so that
+// the first item ends up at index 0 again.
+func (q *Queue[T]) grow() {
+    if q.count < len(q.ring) {
+        return
+    }
+
+    ring := make([]T, max(2*len(q.ring), 4))
+    for i := 0; i < q.count; i++ {
+        ring[i] = q.ring[(q.head+i)%len(q.ring)]
+    }
+    q.ring, q.head = ring, 0
+}
+
+// Double-ended queue, supports adding and removing items at both ends
+type Deque[T any] struct {
+    Queue[T]
+}
+
+func (d *Deque[T]) PushFront(item T) {
+    d.grow()
+    d.head = (d.head - 1 + len(d.ring)) % len(d.ring)
+    d.ring[d.head] = item
+    d.count++
+}
+
+// Removes and returns the last item. Returns false if the deque is empty.
+func (d *Deque[T]) PopBack() (T, bool) {
+    item, ok := d.PeekBack()
+    if ok {
+        var zero T
+        d.ring[(d.head+d.count-1)%len(d.ring)] = zero
+        d.count--
+    }
+    return item, ok
+}
+
+// Returns the last item without removing it
+func (d *Deque[T]) PeekBack() (T, bool) {
+    if d.count == 0 {
+        var zero T
+        return zero, false
+    }
+    return d.ring[(d.head+d.count-1)%len(d.ring)], true
+}
+
+
+///////////////////////////////////////////
+// Listing 65: Generische Priority Queue //
+///////////////////////////////////////////
+
+// Binary heap ordered by less. With a less function like a < b it is a
+// min-heap, inverting the comparison turns it into a max-heap.
+type PriorityQueue[T any] struct {
+    items []T
+    less  func(T, T) bool
+}
+
+func NewPriorityQueue[T any](less func(T, T) bool) *PriorityQueue[T] {
+    return &PriorityQueue[T]{less: less}
+}
+
+func (q *PriorityQueue[T]) Push(item T) {
+    q.items = append(q.items, item)
+
+    // Move the new item up until its parent is not larger
+    for child := len(q.items) - 1; child > 0; {
+        parent := (child - 1) / 2
+        if !q.less(q.items[child], q.items[parent]) {
+            break
+        }
+        q.items[child], q.items[parent] = q.items[parent], q.items[child]
+        child = parent
+    }
+}
+
+// Removes and returns the smallest item. Returns false if the queue is empty.
+func (q *PriorityQueue[T]) Pop() (T, bool) {
+    if len(q.items) == 0 {
+        var zero T
+        return zero, false
+    }
+
+    result := q.items[0]
+    last := len(q.items) - 1
+    q.items[0] = q.items[last]
+    var zero T
+    q.items[last] = zero
+    q.items = q.items[:last]
+
+    // Move the new root down until both children are not smaller
+    for parent := 0; ; {
+        smallest := parent
+        for _, child := range []int{2*parent + 1, 2*parent + 2} {
+            if child < len(q.items) && q.less(q.items[child], q.items[smallest]) {
+                smallest = child
+            }
+        }
+        if smallest == parent {
+            break
+        }
+        q.items[parent], q.items[smallest] = q.items[smallest], q.items[parent]
+        parent = smallest
+    }
+
+    return result, true
+}
+
+// Returns the smallest item without removing it
+func (q *PriorityQueue[T]) Peek() (T, bool) {
+    if len(q.items) == 0 {
+        var zero T
+        return zero, false
+    }
+    return q.items[0], true
+}
+
+func (q *PriorityQueue[T]) Len() int { return len(q.items) }
+
+func main() {
+    // Max-heap ordered by size
+    queue := NewPriorityQueue(func(lhs, rhs sizedLentil) bool { return lhs.size() > rhs.size() })
+    queue.Push(sizedLentil{lentilSize: MEDIUM})
+    queue.Push(sizedLentil{lentilSize: LARGE})
+    queue.Push(sizedLentil{lentilSize: SMALL})
+    for item, ok := queue.Pop(); ok; item, ok = queue.Pop() {
+        fmt.Println("Size:", item.size())
+    }
+}
+
+
+//////////////////////////////////
+// Listing 66: Generische Menge //
+//////////////////////////////////
+
+// Set of unique items, replaces the pattern of using map[T]struct{}
+type Set[T comparable] struct {
+    items map[T]struct{}
+}
+
+func NewSet[T comparable](items ...T) *Set[T] {
+    s := &Set[T]{items: make(map[T]struct{}, len(items))}
+    for _, item := range items {
+        s.Add(item)
+    }
+    return s
+}
+
+func (s *Set[T]) Add(item T) { s.items[item] = struct{}{} }
+
+func (s *Set[T]) Remove(item T) { delete(s.items, item) }
+
+func (s *Set[T]) Contains(item T) bool {
+    _, ok := s.items[item]
+    return ok
+}
+
+func (s *Set[T]) Len() int { return len(s.items) }
+
+// Returns a new set with all items contained in s or other
+func (s *Set[T]) Union(other *Set[T]) *Set[T] {
+    result := NewSet(s.ToSlice()...)
+    for item := range other.items {
+        result.Add(item)
+    }
+    return result
+}
+
+// Returns a new set with all items contained in both s and other
+func (s *Set[T]) Intersection(other *Set[T]) *Set[T] {
+    result := NewSet[T]()
+    for item := range s.items {
+        if other.Contains(item) {
+            result.Add(item)
+        }
+    }
+    return result
+}
+
+// Returns a new set with all items of s that are not contained in other
+func (s *Set[T]) Difference(other *Set[T]) *Set[T] {
+    result := NewSet[T]()
+    for item := range s.items {
+        if !other.Contains(item) {
+            result.Add(item)
+        }
+    }
+    return result
+}
+
+// Returns true if all items of s are contained in other
+func (s *Set[T]) IsSubset(other *Set[T]) bool {
+    for item := range s.items {
+        if !other.Contains(item) {
+            return false
+        }
+    }
+    return true
+}
+
+// Returns all items. Note that the order is unspecified.
+func (s *Set[T]) ToSlice() []T {
+    result := make([]T, 0, len(s.items))
+    for item := range s.items {
+        result = append(result, item)
+    }
+    return result
+}
+
+
+/////////////////////////////////////////////////////
+// Listing 67: Generische doppelt verkettete Liste //
+/////////////////////////////////////////////////////
+
+type listNode[T any] struct {
+    item       T
+    prev, next *listNode[T]
+}
+
+// Doubly linked list. Nodes are not exposed so that the internal structure
+// can change. Use NewLinkedList to create it.
+type LinkedList[T any] struct {
+    // Sentinel node, root.next is the first and root.prev the last node
+    root   listNode[T]
+    length int
+}
+
+func NewLinkedList[T any]() *LinkedList[T] {
+    l := &LinkedList[T]{}
+    l.root.next, l.root.prev = &l.root, &l.root
+    return l
+}
+
+func (l *LinkedList[T]) insertAfter(at *listNode[T], item T) {
+    node := &listNode[T]{item: item, prev: at, next: at.next}
+    at.next.prev = node
+    at.next = node
+    l.length++
+}
+
+func (l *LinkedList[T]) remove(node *listNode[T]) T {
+    node.prev.next = node.next
+    node.next.prev = node.prev
+    l.length--
+    return node.item
+}
+
+func (l *LinkedList[T]) PushFront(item T) { l.insertAfter(&l.root, item) }
+
+func (l *LinkedList[T]) PushBack(item T) { l.insertAfter(l.root.prev, item) }
+
+// Removes and returns the first item. Returns false if the list is empty.
+func (l *LinkedList[T]) PopFront() (T, bool) {
+    if l.length == 0 {
+        var zero T
+        return zero, false
+    }
+    return l.remove(l.root.next), true
+}
+
+// Removes and returns the last item. Returns false if the list is empty.
+func (l *LinkedList[T]) PopBack() (T, bool) {
+    if l.length == 0 {
+        var zero T
+        return zero, false
+    }
+    return l.remove(l.root.prev), true
+}
+
+func (l *LinkedList[T]) Len() int { return l.length }
+
+func (l *LinkedList[T]) ForEach(fn func(T)) {
+    for node := l.root.next; node != &l.root; node = node.next {
+        fn(node.item)
+    }
+}
+
+// Removes all items satisfying predicate and returns the number of
+// removed items
+func (l *LinkedList[T]) Remove(predicate func(T) bool) int {
+    removed := 0
+    for node := l.root.next; node != &l.root; node = node.next {
+        if predicate(node.item) {
+            l.remove(node)
+            removed++
+        }
+    }
+    return removed
+}
+
+// Iterates over the list from front to back
+func (l *LinkedList[T]) Iterator() ForwardIterator[T] {
+    return &listIterator[T]{root: &l.root, current: l.root.next}
+}
+
+type listIterator[T any] struct {
+    root, current *listNode[T]
+}
+
+func (it *listIterator[T]) HasNext() bool { return it.current != it.root }
+
+func (it *listIterator[T]) Next() (T, bool) {
+    if !it.HasNext() {
+        var zero T
+        return zero, false
+    }
+    item := it.current.item
+    it.current = it.current.next
+    return item, true
+}
+
+
+/////////////////////////////////////////////////
+// Listing 68: Ringpuffer mit fester Kapazität //
+/////////////////////////////////////////////////
+
+// FIFO buffer with fixed capacity. In contrast to Queue, it never grows and
+// therefore does not allocate after creation.
+type CircularBuffer[T any] struct {
+    items []T
+    head  int // Index of the next item to read
+    count int
+}
+
+func NewCircularBuffer[T any](capacity int) *CircularBuffer[T] {
+    return &CircularBuffer[T]{items: make([]T, capacity)}
+}
+
+// Adds an item at the end. Returns false if the buffer is full.
+func (b *CircularBuffer[T]) Write(item T) bool {
+    if b.IsFull() {
+        return false
+    }
+    b.items[(b.head+b.count)%len(b.items)] = item
+    b.count++
+    return true
+}
+
+// Removes and returns the oldest item. Returns false if the buffer is empty.
+func (b *CircularBuffer[T]) Read() (T, bool) {
+    var zero T
+    if b.IsEmpty() {
+        return zero, false
+    }
+    item := b.items[b.head]
+    b.items[b.head] = zero
+    b.head = (b.head + 1) % len(b.items)
+    b.count--
+    return item, true
+}
+
+func (b *CircularBuffer[T]) IsFull() bool { return b.count == len(b.items) }
+
+func (b *CircularBuffer[T]) IsEmpty() bool { return b.count == 0 }
+
+func (b *CircularBuffer[T]) Len() int { return b.count }
+
+func (b *CircularBuffer[T]) Cap() int { return len(b.items) }
+
+
+///////////////////////////
+// Listing 69: LRU-Cache //
+///////////////////////////
+
+type lruNode[K comparable, V any] struct {
+    key        K
+    value      V
+    prev, next *lruNode[K, V]
+}
+
+// Cache with limited capacity, evicts the least recently used entry when
+// full. Safe for concurrent use, so it can be used as backend for
+// CacheAside.
+type LRUCache[K comparable, V any] struct {
+    mu       sync.Mutex
+    capacity int
+    items    map[K]*lruNode[K, V]
+    // Sentinel of a doubly linked list, most recently used entry first
+    root lruNode[K, V]
+}
+
+func NewLRUCache[K comparable, V any](capacity int) *LRUCache[K, V] {
+    if capacity < 1 {
+        panic(fmt.Sprintf("NewLRUCache: capacity must be positive, got %d", capacity))
+    }
+    c := &LRUCache[K, V]{capacity: capacity, items: make(map[K]*lruNode[K, V], capacity)}
+    c.root.next, c.root.prev = &c.root, &c.root
+    return c
+}
+
+func (c *LRUCache[K, V]) unlink(node *lruNode[K, V]) {
+    node.prev.next = node.next
+    node.next.prev = node.prev
+}
+
+func (c *LRUCache[K, V]) pushFront(node *lruNode[K, V]) {
+    node.prev, node.next = &c.root, c.root.next
+    c.root.next.prev = node
+    c.root.next = node
+}
+
+// Returns the value for key and marks it as most recently used
+func (c *LRUCache[K, V]) Get(key K) (V, bool) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    node, ok := c.items[key]
+    if !ok {
+        var zero V
+        return zero, false
+    }
+    c.unlink(node)
+    c.pushFront(node)
+    return node.value, true
+}
+
+// Inserts or updates the value for key. Evicts the least recently used
+// entry if the cache is full.
+func (c *LRUCache[K, V]) Put(key K, value V) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    if node, ok := c.items[key]; ok {
+        node.value = value
+        c.unlink(node)
+        c.pushFront(node)
+        return
+    }
+
+    if len(c.items) >= c.capacity {
+        oldest := c.root.prev
+        c.unlink(oldest)
+        delete(c.items, oldest.key)
+    }
+    node := &lruNode[K, V]{key: key, value: value}
+    c.items[key] = node
+    c.pushFront(node)
+}
+
+// Removes the entry for key. Returns false if there was none.
+func (c *LRUCache[K, V]) Delete(key K) bool {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    node, ok := c.items[key]
+    if ok {
+        c.unlink(node)
+        delete(c.items, key)
+    }
+    return ok
+}
+
+func (c *LRUCache[K, V]) Len() int {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    return len(c.items)
+}
+
+func main() {
+    items := []eatOrKeep{ /*...*/ }
+    /* ... */
+
+    // Cache the result of process, at most 100 different inputs
+    cache := NewCacheAside[string, []eatOrKeep](NewLRUCache[string, []eatOrKeep](100))
+    processedItems := ProcessCached(items, func(items []eatOrKeep) string { return fmt.Sprint(items) },
+        func(item eatOrKeep) bool { return !item.shouldEat() }, cache)
+    fmt.Println("Eaten:", len(items)-len(processedItems), "Kept:", len(processedItems))
+}
+
+
+//////////////////////////////////////
+// Listing 70: Cache mit Ablaufzeit //
+//////////////////////////////////////
+
+type ttlEntry[V any] struct {
+    value     V
+    expiresAt time.Time
+}
+
+// Cache whose entries expire after a TTL. Safe for concurrent use.
+type TTLCache[K comparable, V any] struct {
+    mu         sync.Mutex
+    defaultTTL time.Duration
+    items      map[K]ttlEntry[V]
+}
+
+func NewTTLCache[K comparable, V any](defaultTTL time.Duration) *TTLCache[K, V] {
+    return &TTLCache[K, V]{defaultTTL: defaultTTL, items: make(map[K]ttlEntry[V])}
+}
+
+// Stores value with the default TTL
+func (c *TTLCache[K, V]) Put(key K, value V) {
+    c.PutWithTTL(key, value, c.defaultTTL)
+}
+
+func (c *TTLCache[K, V]) PutWithTTL(key K, value V, ttl time.Duration) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.items[key] = ttlEntry[V]{value: value, expiresAt: time.Now().Add(ttl)}
+}
+
+// Returns the value for key if it has not expired yet. Expired entries are
+// removed.
+func (c *TTLCache[K, V]) Get(key K) (V, bool) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    entry, ok := c.items[key]
+    if !ok {
+        var zero V
+        return zero, false
+    }
+    if time.Now().After(entry.expiresAt) {
+        delete(c.items, key)
+        var zero V
+        return zero, false
+    }
+    return entry.value, true
+}
+
+func (c *TTLCache[K, V]) Delete(key K) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    delete(c.items, key)
+}
+
+// Removes all entries
+func (c *TTLCache[K, V]) Flush() {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.items = make(map[K]ttlEntry[V])
+}
+
+// Removes expired entries in the background every defaultTTL until ctx
+// is done. Without a reaper, expired entries are only removed by Get.
+// Panics if the cache has no positive defaultTTL to derive the interval.
+func (c *TTLCache[K, V]) StartReaper(ctx context.Context) {
+    if c.defaultTTL <= 0 {
+        panic(fmt.Sprintf("StartReaper: defaultTTL must be positive, got %v", c.defaultTTL))
+    }
+    go func() {
+        ticker := time.NewTicker(c.defaultTTL)
+        defer ticker.Stop()
+        for {
+            select {
+            case <-ticker.C:
+                c.removeExpired()
+            case <-ctx.Done():
+                return
+            }
+        }
+    }()
+}
+
+func (c *TTLCache[K, V]) removeExpired() {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    now := time.Now()
+    for key, entry := range c.items {
+        if now.After(entry.expiresAt) {
+            delete(c.items, key)
+        }
+    }
+}
+
+
+/////////////////////////////////////////////////////
+// Listing 71: Generischer Graph mit Traversierung //
+/////////////////////////////////////////////////////
+
+var ErrCycle = errors.New("graph contains a cycle")
+
+type graphEdge[V comparable, E any] struct {
+    to   V
+    data E
+}
+
+// Directed graph. E is the type of data attached to edges (weight, label,
+// etc.). Vertices and edges keep their insertion order, so traversals are
+// deterministic. Use NewGraph to create it.
+type Graph[V comparable, E any] struct {
+    vertices []V
+    edges    map[V][]graphEdge[V, E]
+}
+
+func NewGraph[V comparable, E any]() *Graph[V, E] {
+    return &Graph[V, E]{edges: make(map[V][]graphEdge[V, E])}
+}
+
+// Adds a vertex, does nothing if it already exists
+func (g *Graph[V, E]) AddVertex(v V) {
+    if _, ok := g.edges[v]; !ok {
+        g.vertices = append(g.vertices, v)
+        g.edges[v] = []graphEdge[V, E]{}
+    }
+}
+
+// Adds an edge from from to to. Missing vertices are added automatically.
+func (g *Graph[V, E]) AddEdge(from, to V, data E) {
+    g.AddVertex(from)
+    g.AddVertex(to)
+    g.edges[from] = append(g.edges[from], graphEdge[V, E]{to: to, data: data})
+}
+
+// Returns all vertices reachable from v via a single edge
+func (g *Graph[V, E]) Neighbors(v V) []V {
+    return Map(g.edges[v], func(edge graphEdge[V, E]) V { return edge.to })
+}
+
+// Visits all vertices reachable from start in breadth-first order
+func (g *Graph[V, E]) BFS(start V, visitor func(V)) {
+    visited := NewSet(start)
+    var queue Queue[V]
+    queue.Enqueue(start)
+    for v, ok := queue.Dequeue(); ok; v, ok = queue.Dequeue() {
+        visitor(v)
+        for _, neighbor := range g.Neighbors(v) {
+            if !visited.Contains(neighbor) {
+                visited.Add(neighbor)
+                queue.Enqueue(neighbor)
+            }
+        }
+    }
+}
+
+// Visits all vertices reachable from start in depth-first order
+func (g *Graph[V, E]) DFS(start V, visitor func(V)) {
+    visited := NewSet[V]()
+    var stack Stack[V]
+    stack.Push(start)
+    for v, ok := stack.Pop(); ok; v, ok = stack.Pop() {
+        if visited.Contains(v) {
+            continue
+        }
+        visited.Add(v)
+        visitor(v)
+
+        // Push in reverse order so that the first neighbor is visited first
+        neighbors := g.Neighbors(v)
+        for i := len(neighbors) - 1; i >= 0; i-- {
+            if !visited.Contains(neighbors[i]) {
+                stack.Push(neighbors[i])
+            }
+        }
+    }
+}
+
+// Orders all vertices so that every edge points from an earlier to a later
+// vertex (Kahn's algorithm). Returns ErrCycle if that is not possible.
+func (g *Graph[V, E]) TopologicalSort() ([]V, error) {
+    inDegree := make(map[V]int, len(g.vertices))
+    for _, edges := range g.edges {
+        for _, edge := range edges {
+            inDegree[edge.to]++
+        }
+    }
+
+    var ready Queue[V]
+    for _, v := range g.vertices {
+        if inDegree[v] == 0 {
+            ready.Enqueue(v)
+        }
+    }
+
+    result := make([]V, 0, len(g.vertices))
+    for v, ok := ready.Dequeue(); ok; v, ok = ready.Dequeue() {
+        result = append(result, v)
+        for _, neighbor := range g.Neighbors(v) {
+            inDegree[neighbor]--
+            if inDegree[neighbor] == 0 {
+                ready.Enqueue(neighbor)
+            }
+        }
+    }
+
+    if len(result) != len(g.vertices) {
+        return nil, ErrCycle
+    }
+    return result, nil
+}
+
+
+////////////////////////////////////////
+// Listing 72: Generischer Option-Typ //
+////////////////////////////////////////
+
+// Either contains a value (Some) or not (None). Alternative to returning
+// (T, bool) that is easier to chain.
+type Option[T any] struct {
+    value T
+    ok    bool
+}
+
+func Some[T any](v T) Option[T] { return Option[T]{value: v, ok: true} }
+
+// Named NoneOption as None is already used for the predicate function
+// (see listing 57)
+func NoneOption[T any]() Option[T] { return Option[T]{} }
+
+// Converts the common (T, bool) result into an Option, e.g.
+// OptionOf(Find(items, predicate))
+func OptionOf[T any](v T, ok bool) Option[T] {
+    if !ok {
+        return NoneOption[T]()
+    }
+    return Some(v)
+}
+
+func (o Option[T]) IsSome() bool { return o.ok }
+
+func (o Option[T]) IsNone() bool { return !o.ok }
+
+// Returns the value, panics if there is none
+func (o Option[T]) Unwrap() T {
+    if !o.ok {
+        panic("Unwrap called on None")
+    }
+    return o.value
+}
+
+func (o Option[T]) UnwrapOr(fallback T) T {
+    if !o.ok {
+        return fallback
+    }
+    return o.value
+}
+
+// Turns Some into None if the value does not satisfy predicate
+func (o Option[T]) Filter(predicate func(T) bool) Option[T] {
+    if o.ok && !predicate(o.value) {
+        return NoneOption[T]()
+    }
+    return o
+}
+
+// Transforms the value if there is one. Implemented as a function because
+// methods cannot have additional type parameters.
+func MapOption[T, O any](o Option[T], transform func(T) O) Option[O] {
+    if !o.ok {
+        return NoneOption[O]()
+    }
+    return Some(transform(o.value))
+}
+
+func FlatMapOption[T, O any](o Option[T], transform func(T) Option[O]) Option[O] {
+    if !o.ok {
+        return NoneOption[O]()
+    }
+    return transform(o.value)
+}
+
+// Option-based alternative to Find
+func FindOption[I any](items []I, predicate func(I) bool) Option[I] {
+    return OptionOf(Find(items, predicate))
+}
+
+// Option-based alternative to Pop
+func (s *Stack[T]) PopOption() Option[T] {
+    return OptionOf(s.Pop())
+}
+
+func main() {
+    sizedItems := []sizedLentil{ /*...*/ }
+    /* ... */
+
+    size := MapOption(
+        FindOption(sizedItems, func(item sizedLentil) bool { return !item.shouldEat() }),
+        func(item sizedLentil) int { return item.size() })
+    fmt.Println("Size of first kept lentil:", size.UnwrapOr(0))
+}
+
+
+////////////////////////////////////////
+// Listing 73: Generischer Result-Typ //
+////////////////////////////////////////
+
+// Used if Err is called with a nil error, so that an Err result never
+// carries a nil error
+var ErrNilError = errors.New("Err called with nil error")
+
+// Either a value (Ok) or an error (Err)
+type Result[T any] struct {
+    value T
+    err   error
+}
+
+func Ok[T any](v T) Result[T] { return Result[T]{value: v} }
+
+func Err[T any](err error) Result[T] {
+    if err == nil {
+        err = ErrNilError
+    }
+    return Result[T]{err: err}
+}
+
+func (r Result[T]) IsOk() bool { return r.err == nil }
+
+func (r Result[T]) IsErr() bool { return r.err != nil }
+
+// Returns the value, panics if the result is an error
+func (r Result[T]) Unwrap() T {
+    if r.err != nil {
+        panic(fmt.Sprintf("Unwrap called on Err: %v", r.err))
+    }
+    return r.value
+}
+
+// Returns the error, panics if the result is Ok
+func (r Result[T]) UnwrapErr() error {
+    if r.err == nil {
+        panic("UnwrapErr called on Ok")
+    }
+    return r.err
+}
+
+func (r Result[T]) UnwrapOr(fallback T) T {
+    if r.err != nil {
+        return fallback
+    }
+    return r.value
+}
+
+// Transforms the value of an Ok result, errors are passed through.
+// Implemented as a function because methods cannot have additional type
+// parameters.
+func MapResult[T, O any](r Result[T], transform func(T) O) Result[O] {
+    if r.err != nil {
+        return Err[O](r.err)
+    }
+    return Ok(transform(r.value))
+}
+
+
+////////////////////////////////////
+// Listing 74: Generische Futures //
+////////////////////////////////////
+
+// Result of a computation running in the background
+type Future[T any] struct {
+    done  chan struct{}
+    once  sync.Once
+    value T
+    err   error
+}
+
+// Runs fn in a separate goroutine
+func NewFuture[T any](fn func() (T, error)) *Future[T] {
+    f := &Future[T]{done: make(chan struct{})}
+    go func() { f.resolve(fn()) }()
+    return f
+}
+
+// Stores the result and wakes up all waiting goroutines. Only the first
+// call has an effect.
+func (f *Future[T]) resolve(value T, err error) {
+    f.once.Do(func() {
+        f.value, f.err = value, err
+        close(f.done)
+    })
+}
+
+// Blocks until the computation has finished
+func (f *Future[T]) Get() (T, error) {
+    <-f.done
+    return f.value, f.err
+}
+
+// Like Get, but stops waiting when ctx is done. The computation itself
+// keeps running.
+func (f *Future[T]) GetWithContext(ctx context.Context) (T, error) {
+    select {
+    case <-f.done:
+        return f.value, f.err
+    case <-ctx.Done():
+        var zero T
+        return zero, ctx.Err()
+    }
+}
+
+// Returns true if the computation has finished, never blocks
+func (f *Future[T]) IsComplete() bool {
+    select {
+    case <-f.done:
+        return true
+    default:
+        return false
+    }
+}
+
+// Runs fn with the result of f as soon as it is available. Errors of f are
+// passed through without calling fn. Implemented as a function because
+// methods cannot have additional type parameters.
+func Then[T, O any](f *Future[T], fn func(T) (O, error)) *Future[O] {
+    return NewFuture(func() (O, error) {
+        value, err := f.Get()
+        if err != nil {
+            var zero O
+            return zero, err
+        }
+        return fn(value)
+    })
+}
+
+func main() {
+    items := []eatOrKeep{ /*...*/ }
+    /* ... */
+
+    kept := Then(
+        NewFuture(func() ([]eatOrKeep, error) {
+            return process(items, func(item eatOrKeep) bool { return !item.shouldEat() }), nil
+        }),
+        func(processedItems []eatOrKeep) (int, error) { return len(processedItems), nil })
+    count, _ := kept.Get()
+    fmt.Println("Kept:", count)
+}
+
+
+////////////////////////////////////////////
+// Listing 75: Verzögerte Initialisierung //
+////////////////////////////////////////////
+
+// Value that is computed on first access. In contrast to sync.OnceValue,
+// it can be reset to force re-evaluation.
+type Lazy[T any] struct {
+    mu          sync.Mutex
+    once        *sync.Once
+    fn          func() T
+    value       T
+    initialized bool
+}
+
+func NewLazy[T any](fn func() T) *Lazy[T] {
+    return &Lazy[T]{once: &sync.Once{}, fn: fn}
+}
+
+// Computes the value on first call, subsequent calls return the cached value
+func (l *Lazy[T]) Value() T {
+    l.mu.Lock()
+    once := l.once
+    l.mu.Unlock()
+
+    var result T
+    computed := false
+    once.Do(func() {
+        result, computed = l.fn(), true
+        l.mu.Lock()
+        defer l.mu.Unlock()
+        // A Reset during fn replaced once, the result is stale then
+        if l.once == once {
+            l.value, l.initialized = result, true
+        }
+    })
+    if computed {
+        return result
+    }
+
+    l.mu.Lock()
+    if l.once == once {
+        defer l.mu.Unlock()
+        return l.value
+    }
+    l.mu.Unlock()
+    // Reset was called after once had been read, compute again
+    return l.Value()
+}
+
+func (l *Lazy[T]) IsInitialized() bool {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    return l.initialized
+}
+
+// Clears the cached value, the next call to Value computes it again
+func (l *Lazy[T]) Reset() {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    var zero T
+    l.once, l.value, l.initialized = &sync.Once{}, zero, false
+}
+
+
+/////////////////////////////////////////////
+// Listing 76: Memoisierung von Funktionen //
+/////////////////////////////////////////////
+
+// Wraps fn so that it is only called once per input. Safe for concurrent
+// use, but fn may be called more than once if two goroutines ask for the
+// same uncached input at the same time.
+func Memoize[I comparable, O any](fn func(I) O) func(I) O {
+    return MemoizeWithCapacity(fn, 0)
+}
+
+// Like Memoize, but caches at most capacity results. When full, the oldest
+// entry is evicted. A capacity <= 0 means unlimited.
+func MemoizeWithCapacity[I comparable, O any](fn func(I) O, capacity int) func(I) O {
+    var mu sync.RWMutex
+    cache := make(map[I]O)
+    var insertionOrder Queue[I]
+
+    return func(input I) O {
+        mu.RLock()
+        result, ok := cache[input]
+        mu.RUnlock()
+        if ok {
+            return result
+        }
+
+        result = fn(input)
+
+        mu.Lock()
+        defer mu.Unlock()
+        if _, ok := cache[input]; !ok {
+            // The insertion order is only needed for eviction, so
+            // unlimited caches do not keep it
+            if capacity > 0 {
+                if len(cache) >= capacity {
+                    oldest, _ := insertionOrder.Dequeue()
+                    delete(cache, oldest)
+                }
+                insertionOrder.Enqueue(input)
+            }
+        }
+        cache[input] = result
+        return result
+    }
+}
+
+func main() {
+    items := []eatOrKeep{ /*...*/ }
+    /* ... */
+
+    // Expensive decision is made only once per distinct item
+    filter := Memoize(func(item eatOrKeep) bool { return !item.shouldEat() })
+    processedItems := process(items, filter)
+    fmt.Println("Eaten:", len(items)-len(processedItems), "Kept:", len(processedItems))
+}
+
+
+///////////////////////////////////////////////////////////////
+// Listing 77: Wiederholungen mit konfigurierbarer Strategie //
+///////////////////////////////////////////////////////////////
+
+// Decides whether and when to retry. attempt is the number of failed
+// attempts so far (starting at 1). Returns false if no more retries should
+// be made.
+type RetryPolicy interface {
+    NextDelay(attempt int) (time.Duration, bool)
+}
+
+// Adapter to use ordinary functions as RetryPolicy
+type RetryPolicyFunc func(attempt int) (time.Duration, bool)
+
+func (f RetryPolicyFunc) NextDelay(attempt int) (time.Duration, bool) { return f(attempt) }
+
+// Tries at most n times without waiting in between
+func MaxAttempts(n int) RetryPolicy {
+    return RetryPolicyFunc(func(attempt int) (time.Duration, bool) { return 0, attempt < n })
+}
+
+// Waits initial, initial*factor, initial*factor^2, ... capped at max. Retries
+// until the context is cancelled. ExponentialBackoff (see listing 18) is the
+// stateful Backoff counterpart.
+func ExponentialRetryPolicy(initial time.Duration, factor float64, max time.Duration) RetryPolicy {
+    return RetryPolicyFunc(func(attempt int) (time.Duration, bool) {
+        delay := float64(initial) * math.Pow(factor, float64(attempt-1))
+        if delay > float64(max) {
+            return max, true
+        }
+        return time.Duration(delay), true
+    })
+}
+
+// Uses a Backoff (see listing 18) for the delays and gives up after
+// maxAttempts attempts
+func BackoffPolicy(backoff Backoff, maxAttempts int) RetryPolicy {
+    return RetryPolicyFunc(func(attempt int) (time.Duration, bool) {
+        return backoff.Next(), attempt < maxAttempts
+    })
+}
+
+// Calls fn until it succeeds, the policy gives up or ctx is cancelled.
+// Returns the last error of fn if all attempts fail.
+func Retry[T any](ctx context.Context, fn func() (T, error), policy RetryPolicy) (T, error) {
+    for attempt := 1; ; attempt++ {
+        result, err := fn()
+        if err == nil {
+            return result, nil
+        }
+
+        delay, retry := policy.NextDelay(attempt)
+        if !retry {
+            return result, err
+        }
+        if !sleepContext(ctx, delay) {
+            var zero T
+            return zero, errors.Join(err, ctx.Err())
+        }
+    }
+}
+
+
+/////////////////////////////////
+// Listing 78: Circuit Breaker //
+/////////////////////////////////
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type CircuitState int
+
+const (
+    // Calls are passed through
+    Closed CircuitState = iota
+    // Calls fail immediately with ErrCircuitOpen
+    Open
+    // A single trial call decides whether to close or reopen the circuit
+    HalfOpen
+)
+
+func (s CircuitState) String() string {
+    return [...]string{"Closed", "Open", "HalfOpen"}[s]
+}
+
+// Protects calls of type func() (T, error). After maxFailures consecutive
+// failures, the circuit opens. After resetTimeout, one trial call is allowed.
+type CircuitBreaker[T any] struct {
+    mu           sync.Mutex
+    maxFailures  int
+    resetTimeout time.Duration
+    state        CircuitState
+    failures     int
+    openedAt     time.Time
+}
+
+func NewCircuitBreaker[T any](maxFailures int, resetTimeout time.Duration) *CircuitBreaker[T] {
+    return &CircuitBreaker[T]{maxFailures: maxFailures, resetTimeout: resetTimeout}
+}
+
+// Returns the current state. An open circuit whose reset timeout has
+// elapsed is reported as half-open.
+func (cb *CircuitBreaker[T]) State() CircuitState {
+    cb.mu.Lock()
+    defer cb.mu.Unlock()
+    if cb.state == Open && time.Since(cb.openedAt) >= cb.resetTimeout {
+        return HalfOpen
+    }
+    return cb.state
+}
+
+// Calls fn unless the circuit is open
+func (cb *CircuitBreaker[T]) Call(fn func() (T, error)) (T, error) {
+    if !cb.allow() {
+        var zero T
+        return zero, ErrCircuitOpen
+    }
+
+    result, err := fn()
+    cb.record(err)
+    return result, err
+}
+
+// Like Call, but runs fn in the background
+func (cb *CircuitBreaker[T]) CallAsync(fn func() (T, error)) *Future[T] {
+    return NewFuture(func() (T, error) { return cb.Call(fn) })
+}
+
+func (cb *CircuitBreaker[T]) allow() bool {
+    cb.mu.Lock()
+    defer cb.mu.Unlock()
+    switch cb.state {
+    case Open:
+        if time.Since(cb.openedAt) < cb.resetTimeout {
+            return false
+        }
+        // Let exactly one trial call through
+        cb.state = HalfOpen
+        return true
+    case HalfOpen:
+        // Trial call is still running
+        return false
+    default:
+        return true
+    }
+}
+
+func (cb *CircuitBreaker[T]) record(err error) {
+    cb.mu.Lock()
+    defer cb.mu.Unlock()
+    if err == nil {
+        cb.state, cb.failures = Closed, 0
+        return
+    }
+
+    cb.failures++
+    if cb.state == HalfOpen || cb.failures >= cb.maxFailures {
+        cb.state, cb.openedAt = Open, time.Now()
+    }
+}
+
+
+/////////////////////////////////////////////
+// Listing 79: Filter mit Fehlerbehandlung //
+/////////////////////////////////////////////
+
+// Like process, but the filter can fail (e.g. because it asks an external
+// service). Items for which the filter fails are not kept, all errors are
+// returned in the order they occurred.
+func processWithErrors[I any](items []I, filter func(I) (bool, error)) ([]I, []error) {
+    result := []I{}
+    var errs []error
+    for _, item := range items {
+        keep, err := filter(item)
+        if err != nil {
+            errs = append(errs, err)
+            continue
+        }
+        if keep {
+            result = append(result, item)
+        }
+    }
+
+    return result, errs
+}
+
+// Strict variant of processWithErrors, stops at the first error. The items
+// kept up to that point are returned together with the error.
+func processFailFast[I any](items []I, filter func(I) (bool, error)) ([]I, error) {
+    result := []I{}
+    for _, item := range items {
+        keep, err := filter(item)
+        if err != nil {
+            return result, err
+        }
+        if keep {
+            result = append(result, item)
+        }
+    }
+
+    return result, nil
+}
+
+// Variant of processWithErrors with a single result slice (see Result in
+// listing 73). Kept items become Ok, failed filter calls become Err, both
+// in the order of items. Dropped items do not show up at all.
+func processResults[I any](items []I, filter func(I) (bool, error)) []Result[I] {
+    results := []Result[I]{}
+    for _, item := range items {
+        keep, err := filter(item)
+        if err != nil {
+            results = append(results, Err[I](err))
+            continue
+        }
+        if keep {
+            results = append(results, Ok(item))
+        }
+    }
+
+    return results
+}
+
+
+////////////////////////////////////////////////////////
+// Listing 80: Filter mit Index als zweitem Parameter //
+////////////////////////////////////////////////////////
+
+// Like process, but the filter also receives the zero-based index of the
+// item. Same as ProcessWithIndex (see listing 22) with the parameters in the
+// order of process' filter followed by the index.
+func processIndexed[I any](items []I, filter func(I, int) bool) []I {
+    return ProcessWithIndex(items, func(index int, item I) bool { return filter(item, index) })
+}
+
+func main() {
+    items := []eatOrKeep{ /*...*/ }
+    /* ... */
+
+    // Sampling: keep every second item
+    sample := processIndexed(items, func(item eatOrKeep, index int) bool { return index%2 == 0 })
+    fmt.Println("Sample size:", len(sample))
+}
+
+
+///////////////////////////////////////////////////////////////
+// Listing 81: Filtern und Transformieren in einem Durchlauf //
+///////////////////////////////////////////////////////////////
+
+// Combines process and Map: transforms all items for which filter returns
+// true without allocating an intermediate slice.
+func processAndTransform[I, O any](items []I, filter func(I) bool, transform func(I) O) []O {
+    result := []O{}
+    for _, item := range items {
+        if filter(item) {
+            result = append(result, transform(item))
+        }
+    }
+
+    return result
+}
+
+type lentilSummary struct {
+    size int
+}
+
+func main() {
+    sizedItems := []sizedLentil{ /*...*/ }
+    /* ... */
+
+    report := processAndTransform(sizedItems,
+        func(item sizedLentil) bool { return !item.shouldEat() },
+        func(item sizedLentil) lentilSummary { return lentilSummary{size: item.size()} })
+    fmt.Println("Report:", report)
+}
+
+
+///////////////////////////////////////////////////////
+// Listing 82: Parallele Verarbeitung mit Goroutinen //
+///////////////////////////////////////////////////////
+
+// Parallel version of process. The items are split into one chunk per
+// worker, each worker filters its chunk into its own result slice, so no
+// locking is necessary. The order of items is preserved. workers <= 0
+// means one worker per CPU.
+func processParallel[I any](items []I, filter func(I) bool, workers int) []I {
+    if workers <= 0 {
+        workers = runtime.NumCPU()
+    }
+    chunkSize := (len(items) + workers - 1) / workers
+    if chunkSize == 0 {
+        return []I{}
+    }
+
+    results := make([][]I, (len(items)+chunkSize-1)/chunkSize)
+    var wg sync.WaitGroup
+    for worker := range results {
+        start := worker * chunkSize
+        end := min(start+chunkSize, len(items))
+        wg.Add(1)
+        go func(worker int, chunk []I) {
+            defer wg.Done()
+            results[worker] = process(chunk, filter)
+        }(worker, items[start:end])
+    }
+    wg.Wait()
+
+    return Concat(results...)
+}
+
+
+//////////////////////////////////////////
+// Listing 83: Parallele Transformation //
+//////////////////////////////////////////
+
+// Parallel version of Map. Each goroutine writes to its own indices of the
+// pre-allocated result, so no mutex is needed. transform must be safe for
+// concurrent use. concurrency <= 0 means one goroutine per CPU.
+func ParallelMap[I, O any](items []I, transform func(I) O, concurrency int) []O {
+    if concurrency <= 0 {
+        concurrency = runtime.NumCPU()
+    }
+
+    result := make([]O, len(items))
+    indices := make(chan int)
+    var wg sync.WaitGroup
+    for worker := 0; worker < concurrency; worker++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for index := range indices {
+                result[index] = transform(items[index])
+            }
+        }()
+    }
+
+    for index := range items {
+        indices <- index
+    }
+    close(indices)
+    wg.Wait()
+
+    return result
+}
+
+
+///////////////////////////////////////////////////////
+// Listing 84: Abbrechbare Verarbeitung von Channels //
+///////////////////////////////////////////////////////
+
+// Like processChannel, but stops and closes the output as soon as ctx is
+// cancelled, even if input is still open.
+func processChannelWithContext[I any](ctx context.Context, in <-chan I, filter func(I) bool) <-chan I {
+    out := make(chan I)
+    go func() {
+        defer close(out)
+        for {
+            select {
+            case item, ok := <-in:
+                if !ok {
+                    return
+                }
+                if !filter(item) {
+                    continue
+                }
+                select {
+                case out <- item:
+                case <-ctx.Done():
+                    return
+                }
+            case <-ctx.Done():
+                return
+            }
+        }
+    }()
+    return out
+}
+
+
+/////////////////////////////////////////////
+// Listing 85: Transformation von Channels //
+/////////////////////////////////////////////
+
+// Channel equivalent of Map
+func MapChannel[I, O any](in <-chan I, transform func(I) O) <-chan O {
+    out := make(chan O)
+    go func() {
+        defer close(out)
+        for item := range in {
+            out <- transform(item)
+        }
+    }()
+    return out
+}
+
+// Like MapChannel, but stops and closes the output when ctx is cancelled
+func MapChannelWithContext[I, O any](ctx context.Context, in <-chan I, transform func(I) O) <-chan O {
+    out := make(chan O)
+    go func() {
+        defer close(out)
+        for {
+            select {
+            case item, ok := <-in:
+                if !ok {
+                    return
+                }
+                select {
+                case out <- transform(item):
+                case <-ctx.Done():
+                    return
+                }
+            case <-ctx.Done():
+                return
+            }
+        }
+    }()
+    return out
+}
+
+func main() {
+    in := make(chan sizedLentil, 3)
+    in <- sizedLentil{lentilSize: LARGE, lentil: lentil{isGood: true}}
+    in <- sizedLentil{lentilSize: MEDIUM, lentil: lentil{isGood: false}}
+    in <- sizedLentil{lentilSize: SMALL, lentil: lentil{isGood: true}}
+    close(in)
+
+    // processChannel -> MapChannel -> collect
+    kept := processChannel(in, func(item sizedLentil) bool { return !item.shouldEat() })
+    for size := range MapChannel(kept, func(item sizedLentil) int { return item.size() }) {
+        fmt.Println("Size:", size)
+    }
+}
+
+
+//////////////////////////////////////////////////////////////////
+// Listing 86: Verteilen eines Channels auf mehrere Konsumenten //
+//////////////////////////////////////////////////////////////////
+
+type FanOutMode int
+
+const (
+    // Every consumer receives every item
+    Broadcast FanOutMode = iota
+    // Items are distributed to the consumers in turn (work queue)
+    RoundRobin
+)
+
+// Distributes the items of in to n output channels. All outputs are closed
+// when in is closed.
+func FanOut[I any](in <-chan I, n int, mode FanOutMode) []<-chan I {
+    if n < 1 {
+        panic(fmt.Sprintf("FanOut: n must be positive, got %d", n))
+    }
+    inputs := make([]chan I, n)
+    outputs := make([]<-chan I, n)
+    for i := range inputs {
+        inputs[i] = make(chan I)
+        if mode == Broadcast {
+            // A slow consumer must not block the others, so every
+            // consumer gets its own buffer
+            outputs[i] = unboundedBuffer(inputs[i])
+        } else {
+            outputs[i] = inputs[i]
+        }
+    }
+
+    go func() {
+        defer func() {
+            for _, input := range inputs {
+                close(input)
+            }
+        }()
+
+        next := 0
+        for item := range in {
+            if mode == Broadcast {
+                for _, input := range inputs {
+                    input <- item
+                }
+            } else {
+                inputs[next] <- item
+                next = (next + 1) % n
+            }
+        }
+    }()
+    return outputs
+}
+
+// Forwards all items of in, buffering as many of them as necessary. Reading
+// from in therefore never blocks for long, regardless of the consumer.
+func unboundedBuffer[I any](in <-chan I) <-chan I {
+    out := make(chan I)
+    go func() {
+        defer close(out)
+        var buffer Queue[I]
+        for in != nil || !buffer.IsEmpty() {
+            // Sending on a nil channel blocks forever, which disables the
+            // case as long as there is nothing to send
+            var send chan I
+            next, ok := buffer.Peek()
+            if ok {
+                send = out
+            }
+
+            select {
+            case item, ok := <-in:
+                if !ok {
+                    in = nil
+                    continue
+                }
+                buffer.Enqueue(item)
+            case send <- next:
+                buffer.Dequeue()
+            }
+        }
+    }()
+    return out
+}
+
+
+//////////////////////////////////////////////////
+// Listing 87: Zusammenführen mehrerer Channels //
+//////////////////////////////////////////////////
+
+// Merges all channels into one. Every input is read by its own goroutine,
+// so a slow input does not hold back the others. The output is closed when
+// all inputs are closed. Use ConcatChannels if the order matters.
+func FanIn[I any](channels ...<-chan I) <-chan I {
+    return FanInWithContext(context.Background(), channels...)
+}
+
+// Like FanIn, but stops merging and closes the output when ctx is cancelled
+func FanInWithContext[I any](ctx context.Context, channels ...<-chan I) <-chan I {
+    out := make(chan I)
+    var wg sync.WaitGroup
+    for _, channel := range channels {
+        wg.Add(1)
+        go func(channel <-chan I) {
+            defer wg.Done()
+            for item := range processChannelWithContext(ctx, channel, func(I) bool { return true }) {
+                select {
+                case out <- item:
+                case <-ctx.Done():
+                    return
+                }
+            }
+        }(channel)
+    }
+
+    go func() {
+        wg.Wait()
+        close(out)
+    }()
+    return out
+}
+
+
+///////////////////////////////////////////////
+// Listing 88: Bündeln von Channel-Elementen //
+///////////////////////////////////////////////
+
+// Collects items into batches of up to size items. A batch is emitted when
+// it is full or timeout has elapsed since its first item arrived, whichever
+// comes first. Empty batches are never emitted, a partial batch is flushed
+// when in is closed.
+func BatchChannel[I any](in <-chan I, size int, timeout time.Duration) <-chan []I {
+    if size < 1 {
+        panic(fmt.Sprintf("BatchChannel: size must be positive, got %d", size))
+    }
+    out := make(chan []I)
+    go func() {
+        defer close(out)
+        var batch []I
+        var timer *time.Timer
+        var expired <-chan time.Time
+        flush := func() {
+            if timer != nil {
+                timer.Stop()
+                timer, expired = nil, nil
+            }
+            if len(batch) > 0 {
+                out <- batch
+                batch = nil
+            }
+        }
+
+        for {
+            select {
+            case item, ok := <-in:
+                if !ok {
+                    flush()
+                    return
+                }
+                batch = append(batch, item)
+                if len(batch) == 1 {
+                    timer = time.NewTimer(timeout)
+                    expired = timer.C
+                }
+                if len(batch) == size {
+                    flush()
+                }
+            case <-expired:
+                flush()
+            }
+        }
+    }()
+    return out
+}
+
+
+////////////////////////////////////////////
+// Listing 89: Duplizieren eines Channels //
+////////////////////////////////////////////
+
+// Returns two channels that both receive every item of in. One branch may
+// run ahead of the other by up to slack items, after that the faster branch
+// has to wait. Both outputs are closed when in is closed.
+func TeeChannel[I any](in <-chan I, slack int) (<-chan I, <-chan I) {
+    first, second := make(chan I, slack), make(chan I, slack)
+    go func() {
+        defer close(first)
+        defer close(second)
+        for item := range in {
+            // Deliver to whichever branch is ready first. A nil channel
+            // disables its case once the item has been delivered there.
+            a, b := first, second
+            for a != nil || b != nil {
+                select {
+                case a <- item:
+                    a = nil
+                case b <- item:
+                    b = nil
+                }
+            }
+        }
+    }()
+    return first, second
+}
+
+
+/////////////////////////////////////////////////
+// Listing 90: Gleitende Fenster über Channels //
+/////////////////////////////////////////////////
+
+// Emits windows of size items, each window starting step items after the
+// previous one. With step == size, windows are adjacent (tumbling), with
+// step < size they overlap (sliding) and with step > size, items between
+// windows are skipped (hopping). If in is closed mid-window, the partial
+// window is emitted, too.
+func WindowChannel[I any](in <-chan I, size int, step int) <-chan []I {
+    if size < 1 || step < 1 {
+        panic(fmt.Sprintf("WindowChannel: size and step must be positive, got %d and %d", size, step))
+    }
+    out := make(chan []I)
+    go func() {
+        defer close(out)
+        window := make([]I, 0, size)
+        skip := 0  // Items to skip before the next window starts
+        fresh := 0 // Items in window that have not been emitted yet
+        for item := range in {
+            if skip > 0 {
+                skip--
+                continue
+            }
+
+            window = append(window, item)
+            fresh++
+            if len(window) < size {
+                continue
+            }
+
+            out <- append([]I{}, window...)
+            fresh = 0
+            if step < size {
+                window = append(window[:0], window[step:]...)
+            } else {
+                window = window[:0]
+                skip = step - size
+            }
+        }
+
+        if fresh > 0 {
+            out <- window
+        }
+    }()
+    return out
+}
+
+
+////////////////////////////////////////////////////
+// Listing 91: Laufende Aggregation über Channels //
+////////////////////////////////////////////////////
+
+// Channel equivalent of a left scan: emits the accumulated value after
+// each item. Same as StepReduce (see listing 45), provided under the name
+// commonly used in functional libraries.
+func ScanChannel[I, O any](in <-chan I, initial O, accumulator func(O, I) O) <-chan O {
+    return StepReduce(in, initial, accumulator)
+}
+
+func main() {
+    in := make(chan eatOrKeep, 4)
+    in <- lentil{isGood: true}
+    in <- lentil{isGood: false}
+    in <- snail{hasHouse: true}
+    in <- snail{hasHouse: false}
+    close(in)
+
+    // Running total of kept items
+    kept := processChannel(in, func(item eatOrKeep) bool { return !item.shouldEat() })
+    for total := range ScanChannel(kept, 0, func(count int, item eatOrKeep) int { return count + 1 }) {
+        fmt.Println("Kept so far:", total)
+    }
+}
+
+
+///////////////////////////////////////////////////////////
+// Listing 92: Entfernen von Gruppen aus genericItemsBag //
+///////////////////////////////////////////////////////////
+
+// Removes all groups whose item satisfies predicate. Returns the number of
+// removed items (not groups). Remaining groups are not merged, even if
+// they become adjacent and equal.
+func (b *genericItemsBag[T]) Remove(predicate func(T) bool) int {
+    removed := 0
+    remaining := b.bag[:0]
+    for _, group := range b.bag {
+        if predicate(group.item) {
+            removed += group.count
+        } else {
+            remaining = append(remaining, group)
+        }
+    }
+
+    // Clear the now unused tail so that the garbage collector can free the items
+    clear(b.bag[len(remaining):])
+    b.bag = remaining
+    return removed
+}
+
+
+///////////////////////////////////////////
+// Listing 93: Größe von genericItemsBag //
+///////////////////////////////////////////
+
+// Returns the number of items, i.e. the sum of all group counts
+func (b genericItemsBag[T]) Len() int {
+    total := 0
+    for _, group := range b.bag {
+        total += group.count
+    }
+    return total
+}
+
+// Returns the number of groups of consecutive equal items
+func (b genericItemsBag[T]) GroupCount() int { return len(b.bag) }
+
+// Returns the capacity of the underlying group slice
+func (b genericItemsBag[T]) Cap() int { return cap(b.bag) }
+
+
+////////////////////////////////////////////////
+// Listing 94: Iteration über genericItemsBag //
+////////////////////////////////////////////////
+
+// Calls fn once per group with the group's item and count
+func (b genericItemsBag[T]) ForEach(fn func(item T, count int)) {
+    for _, group := range b.bag {
+        fn(group.item, group.count)
+    }
+}
+
+// Calls fn once per item, just like iterating over getItems() but without
+// allocating a slice
+func (b genericItemsBag[T]) ForEachItem(fn func(item T)) {
+    b.ForEach(func(item T, count int) {
+        for i := 0; i < count; i++ {
+            fn(item)
+        }
+    })
+}
+
+
+/////////////////////////////////////////////////////////////////
+// Listing 95: Kopieren und Zusammenführen von genericItemsBag //
+/////////////////////////////////////////////////////////////////
+
+// Like append, but adds count items at once. Merges with the last group if
+// the items are equal.
+func (b *genericItemsBag[T]) appendGroup(item T, count int) {
+    if len(b.bag) == 0 || !b.equalityComparer(item, b.bag[len(b.bag)-1].item) {
+        b.bag = append(b.bag, genericItemsGroup[T]{item: item, count: count})
+    } else {
+        b.bag[len(b.bag)-1].count += count
+    }
+}
+
+// Returns a copy with independent storage, sharing the comparer. Note that
+// the items themselves are copied by value.
+func (b *genericItemsBag[T]) Clone() *genericItemsBag[T] {
+    return &genericItemsBag[T]{
+        bag:              append(make([]genericItemsGroup[T], 0, len(b.bag)), b.bag...),
+        equalityComparer: b.equalityComparer,
+    }
+}
+
+// Appends all groups of other. If the last item of b and the first item of
+// other are equal according to b's comparer, their groups are merged.
+func (b *genericItemsBag[T]) Merge(other *genericItemsBag[T]) {
+    for _, group := range other.bag {
+        b.appendGroup(group.item, group.count)
+    }
+}
+
+
+/////////////////////////////////////////////////////////
+// Listing 96: JSON-Serialisierung von genericItemsBag //
+/////////////////////////////////////////////////////////
+
+var ErrMissingComparer = errors.New("genericItemsBag has no equality comparer")
+
+// Serializes the bag as JSON array of all items. Groups are expanded as the
+// comparer cannot be serialized.
+func (b genericItemsBag[T]) MarshalJSON() ([]byte, error) {
+    return json.Marshal(b.getItems())
+}
+
+// Replaces the content of the bag with the items of a JSON array. The bag
+// must already have a comparer, e.g. created with newGenericItemsBag.
+func (b *genericItemsBag[T]) UnmarshalJSON(data []byte) error {
+    if b.equalityComparer == nil {
+        return ErrMissingComparer
+    }
+
+    var items []T
+    if err := json.Unmarshal(data, &items); err != nil {
+        return err
+    }
+
+    b.bag = make([]genericItemsGroup[T], 0)
+    for _, item := range items {
+        b.append(item)
+    }
+    return nil
+}
+
+func NewGenericItemsBagFromJSON[T any](data []byte, comparer func(T, T) bool) (*genericItemsBag[T], error) {
+    bag := newGenericItemsBag(comparer)
+    if err := bag.UnmarshalJSON(data); err != nil {
+        return nil, err
+    }
+    return bag, nil
+}
+
+func main() {
+    bag, err := NewGenericItemsBagFromJSON([]byte(`[1, 1, 3, 3, 3, 2]`), func(lhs, rhs int) bool { return lhs == rhs })
+    if err != nil {
+        log.Fatal(err)
+    }
+    data, _ := json.Marshal(bag)
+    fmt.Println("Groups:", bag.GroupCount(), "JSON:", string(data))
+}
+
+
+////////////////////////////////////////////////////////////
+// Listing 97: Threadsichere Variante von genericItemsBag //
+////////////////////////////////////////////////////////////
+
+// genericItemsBag protected by a read-write mutex, e.g. for collecting the
+// results of multiple processChannel goroutines. The bag is kept in a named
+// field, so none of its unprotected methods are promoted.
+type concurrentGenericItemsBag[T any] struct {
+    mu  sync.RWMutex
+    bag genericItemsBag[T]
+}
+
+func newConcurrentGenericItemsBag[T any](comparer func(T, T) bool) *concurrentGenericItemsBag[T] {
+    return &concurrentGenericItemsBag[T]{bag: *newGenericItemsBag(comparer)}
+}
+
+func (b *concurrentGenericItemsBag[T]) Append(item T) {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    b.bag.append(item)
+}
+
+func (b *concurrentGenericItemsBag[T]) GetItems() []T {
+    b.mu.RLock()
+    defer b.mu.RUnlock()
+    return b.bag.getItems()
+}
+
+func (b *concurrentGenericItemsBag[T]) Len() int {
+    b.mu.RLock()
+    defer b.mu.RUnlock()
+    return b.bag.Len()
+}
+
+func (b *concurrentGenericItemsBag[T]) GroupCount() int {
+    b.mu.RLock()
+    defer b.mu.RUnlock()
+    return b.bag.GroupCount()
+}
+
+func (b *concurrentGenericItemsBag[T]) Remove(predicate func(T) bool) int {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    return b.bag.Remove(predicate)
+}
+
+// fn runs while the read lock is held, so it must not modify the bag
+func (b *concurrentGenericItemsBag[T]) ForEach(fn func(item T, count int)) {
+    b.mu.RLock()
+    defer b.mu.RUnlock()
+    b.bag.ForEach(fn)
+}
+
+func (b *concurrentGenericItemsBag[T]) MarshalJSON() ([]byte, error) {
+    b.mu.RLock()
+    defer b.mu.RUnlock()
+    return b.bag.MarshalJSON()
+}
+
+// Returns a copy of all items as they are at the time of the call
+func (b *concurrentGenericItemsBag[T]) Snapshot() []T {
+    return b.GetItems()
+}
+
+func main() {
+    in := make(chan eatOrKeep)
+    /* ... */
+
+    // Multiple goroutines feed their results into the same bag
+    bag := newConcurrentGenericItemsBag(func(lhs eatOrKeep, rhs eatOrKeep) bool { return lhs.shouldEat() == rhs.shouldEat() })
+    var wg sync.WaitGroup
+    for worker := 0; worker < 4; worker++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for item := range processChannel(in, func(item eatOrKeep) bool { return !item.shouldEat() }) {
+                bag.Append(item)
+            }
+        }()
+    }
+    wg.Wait()
+    fmt.Println("Kept:", bag.Len())
+}
+
+
+/////////////////////////////////////////////
+// Listing 98: Filtern von genericItemsBag //
+/////////////////////////////////////////////
+
+// Returns a new bag with only the groups whose item satisfies predicate.
+// Groups that become adjacent and are equal according to the comparer are
+// merged.
+func (b *genericItemsBag[T]) Filter(predicate func(T) bool) *genericItemsBag[T] {
+    result := newGenericItemsBag(b.equalityComparer)
+    for _, group := range b.bag {
+        if predicate(group.item) {
+            result.appendGroup(group.item, group.count)
+        }
+    }
+    return result
+}
+
+
+///////////////////////////////////////////////
+// Listing 99: Sortieren von genericItemsBag //
+///////////////////////////////////////////////
+
+// Reorders the groups of the bag by ascending key (stable), merging groups
+// that become adjacent and are equal. Implemented as a function because
+// methods cannot have additional type parameters.
+func Sort[T any, O constraints.Ordered](b *genericItemsBag[T], keyFn func(T) O) {
+    groups := b.bag
+    sort.SliceStable(groups, func(i, j int) bool { return keyFn(groups[i].item) < keyFn(groups[j].item) })
+
+    b.bag = make([]genericItemsGroup[T], 0, len(groups))
+    for _, group := range groups {
+        b.appendGroup(group.item, group.count)
+    }
+}
+
+func main() {
+    genericBag := newGenericItemsBag(func(lhs, rhs sizedLentil) bool { return lhs.size() == rhs.size() })
+    genericBag.append(sizedLentil{lentilSize: LARGE})
+    genericBag.append(sizedLentil{lentilSize: SMALL})
+    genericBag.append(sizedLentil{lentilSize: LARGE})
+
+    // Results in two groups: one SMALL and two LARGE lentils
+    Sort(genericBag, func(item sizedLentil) int { return item.size() })
+    genericBag.ForEach(func(item sizedLentil, count int) { fmt.Println("Size:", item.size(), "Count:", count) })
+}
+
+
+/////////////////////////////////////////
+// Listing 100: Absteigende Sortierung //
+/////////////////////////////////////////
+
+// Like processAndSort, but sorts large to small
+func processAndSortDescending[I sizedEatOrKeep](items []I, filter func(i I) bool) []I {
+    result := process(items, filter)
+    bubblesortDescending(result, func(item I) int { return item.size() })
+    return result
+}
+
+// Like bubblesort, but with reversed comparison
+func bubblesortDescending[I any, O constraints.Ordered](items []I, toOrdered func(item I) O) {
+    bubblesortFunc(items, func(lhs, rhs I) bool { return toOrdered(lhs) < toOrdered(rhs) })
+}
+
+// Bubble sort with a custom comparison. Swaps neighbours as long as
+// outOfOrder returns true for them.
+func bubblesortFunc[I any](items []I, outOfOrder func(lhs, rhs I) bool) {
+    for itemCount := len(items) - 1; ; itemCount-- {
+        hasChanged := false
+        for index := 0; index < itemCount; index++ {
+            if outOfOrder(items[index], items[index+1]) {
+                items[index], items[index+1] = items[index+1], items[index]
+                hasChanged = true
+            }
+        }
+        if !hasChanged {
+            break
+        }
+    }
+}
+
+func main() {
+    sizedItems := []sizedEatOrKeep{
+        sizedLentil{lentilSize: SMALL, lentil: lentil{isGood: true}},
+        sizedLentil{lentilSize: MEDIUM, lentil: lentil{isGood: false}},
+        sizedLentil{lentilSize: LARGE, lentil: lentil{isGood: true}},
+    }
+    processedOrderd := processAndSortDescending(sizedItems, func(item sizedEatOrKeep) bool { return !item.shouldEat() })
+    for _, sortedItem := range processedOrderd {
+        fmt.Println("Size:", sortedItem.size())
+    }
+}
+
+
+////////////////////////////////////////////////////
+// Listing 101: Stabile Sortierung mit Merge Sort //
+////////////////////////////////////////////////////
+
+// Sorts items by ascending key. Items with equal keys keep their relative
+// order (stable). bubblesort is stable, too, but needs O(n²) comparisons;
+// StableSort uses merge sort and needs O(n log n). Drop-in replacement for
+// bubblesort.
+func StableSort[I any, O constraints.Ordered](items []I, keyFn func(I) O) {
+    if len(items) < 2 {
+        return
+    }
+
+    // Calculate keys only once per item
+    keyed := Map(items, func(item I) Pair[O, I] { return Pair[O, I]{First: keyFn(item), Second: item} })
+    buffer := make([]Pair[O, I], len(keyed))
+    mergeSort(keyed, buffer)
+    for index, pair := range keyed {
+        items[index] = pair.Second
+    }
+}
+
+// Top-down merge sort of items, buffer must have the same length
+func mergeSort[O constraints.Ordered, I any](items, buffer []Pair[O, I]) {
+    if len(items) < 2 {
+        return
+    }
+
+    middle := len(items) / 2
+    mergeSort(items[:middle], buffer[:middle])
+    mergeSort(items[middle:], buffer[middle:])
+
+    left, right := 0, middle
+    for index := range buffer {
+        // Taking from the left half on equal keys makes the sort stable
+        if right >= len(items) || (left < middle && items[left].First <= items[right].First) {
+            buffer[index] = items[left]
+            left++
+        } else {
+            buffer[index] = items[right]
+            right++
+        }
+    }
+    copy(items, buffer)
+}
+
+
+/////////////////////////////////////////////
+// Listing 102: Quicksort für große Slices //
+/////////////////////////////////////////////
+
+// Below this length, QuickSort falls back to insertion sort
+const insertionSortThreshold = 12
+
+// Sorts items in place by ascending key using quicksort with three-way
+// partitioning, so many equal keys (like the three lentil sizes) do not
+// degrade performance. Not stable, use StableSort if that matters.
+func QuickSort[I any, O constraints.Ordered](items []I, keyFn func(I) O) {
+    for len(items) > insertionSortThreshold {
+        // Median of three as pivot avoids the worst case for sorted input
+        first, middle, last := keyFn(items[0]), keyFn(items[len(items)/2]), keyFn(items[len(items)-1])
+        pivot := max(min(first, middle), min(max(first, middle), last))
+
+        // Dutch national flag partitioning: [0, lt) < pivot,
+        // [lt, i) == pivot, (gt, len) > pivot
+        lt, i, gt := 0, 0, len(items)-1
+        for i <= gt {
+            switch key := keyFn(items[i]); {
+            case key < pivot:
+                items[lt], items[i] = items[i], items[lt]
+                lt++
+                i++
+            case key > pivot:
+                items[i], items[gt] = items[gt], items[i]
+                gt--
+            default:
+                i++
+            }
+        }
+
+        // Recurse into the smaller part, loop over the larger one to limit
+        // the recursion depth
+        if lt < len(items)-gt-1 {
+            QuickSort(items[:lt], keyFn)
+            items = items[gt+1:]
+        } else {
+            QuickSort(items[gt+1:], keyFn)
+            items = items[:lt]
+        }
+    }
+
+    insertionSort(items, keyFn)
+}
+
+func insertionSort[I any, O constraints.Ordered](items []I, keyFn func(I) O) {
+    for i := 1; i < len(items); i++ {
+        for j := i; j > 0 && keyFn(items[j]) < keyFn(items[j-1]); j-- {
+            items[j], items[j-1] = items[j-1], items[j]
+        }
+    }
+}
+
+
+////////////////////////////////////////////////////
+// Listing 103: Binäre Suche in sortierten Slices //
+////////////////////////////////////////////////////
+
+// Returns the index of an item whose key equals target. If there is none,
+// returns the index where such an item would have to be inserted and false.
+// items must be sorted by keyFn, e.g. by processAndSort.
+func BinarySearch[I any, O constraints.Ordered](items []I, target O, keyFn func(I) O) (int, bool) {
+    index := LowerBound(items, target, keyFn)
+    return index, index < len(items) && keyFn(items[index]) == target
+}
+
+// Returns the first index at which target could be inserted while keeping
+// items sorted (i.e. the index of the first item with key >= target)
+func LowerBound[I any, O constraints.Ordered](items []I, target O, keyFn func(I) O) int {
+    return searchSorted(items, keyFn, func(key O) bool { return key >= target })
+}
+
+// Returns the last index at which target could be inserted while keeping
+// items sorted (i.e. the index of the first item with key > target)
+func UpperBound[I any, O constraints.Ordered](items []I, target O, keyFn func(I) O) int {
+    return searchSorted(items, keyFn, func(key O) bool { return key > target })
+}
+
+// Returns the index of the first item for which found returns true. found
+// must be false for a prefix of items and true for the rest.
+func searchSorted[I any, O constraints.Ordered](items []I, keyFn func(I) O, found func(O) bool) int {
+    // Checking the order is O(n), so it is only done when running tests
+    if testing.Testing() {
+        for index := 1; index < len(items); index++ {
+            if keyFn(items[index]) < keyFn(items[index-1]) {
+                panic(fmt.Sprintf("binary search on unsorted slice: item %d is smaller than item %d", index, index-1))
+            }
+        }
+    }
+
+    low, high := 0, len(items)
+    for low < high {
+        middle := int(uint(low+high) >> 1)
+        if found(keyFn(items[middle])) {
+            high = middle
+        } else {
+            low = middle + 1
+        }
+    }
+    return low
+}
+
+
+//////////////////////////////////////////////////////
+// Listing 104: Sortierung nach mehreren Schlüsseln //
+//////////////////////////////////////////////////////
+
+// Sorts items by the first comparator, items that are equal according to it
+// by the second one, and so on. Comparators return a negative number if
+// lhs comes first, a positive one if rhs comes first and 0 if they are
+// equal. Items equal according to all comparators keep their order.
+func SortByMultiple[I any](items []I, comparators ...func(I, I) int) {
+    sort.SliceStable(items, func(i, j int) bool {
+        for _, compare := range comparators {
+            if result := compare(items[i], items[j]); result != 0 {
+                return result < 0
+            }
+        }
+        return false
+    })
+}
+
+// Builds a comparator ordering by ascending key
+func Ascending[I any, O constraints.Ordered](keyFn func(I) O) func(I, I) int {
+    return func(lhs, rhs I) int {
+        lhsKey, rhsKey := keyFn(lhs), keyFn(rhs)
+        switch {
+        case lhsKey < rhsKey:
+            return -1
+        case lhsKey > rhsKey:
+            return 1
+        default:
+            return 0
+        }
+    }
+}
+
+// Builds a comparator ordering by descending key
+func Descending[I any, O constraints.Ordered](keyFn func(I) O) func(I, I) int {
+    ascending := Ascending(keyFn)
+    return func(lhs, rhs I) int { return ascending(rhs, lhs) }
+}
+
+func main() {
+    sizedItems := []sizedLentil{ /*...*/ }
+    /* ... */
+
+    // Good lentils first, within each group large to small
+    SortByMultiple(sizedItems,
+        Ascending(func(item sizedLentil) int {
+            if item.shouldEat() {
+                return 1
+            }
+            return 0
+        }),
+        Descending(func(item sizedLentil) int { return item.size() }))
+}
+
+
+//////////////////////////////////////////
+// Listing 105: Mischen und Stichproben //
+//////////////////////////////////////////
+
+// Returns rng, or a new time-seeded source if rng is nil
+func randOrDefault(rng *rand.Rand) *rand.Rand {
+    if rng == nil {
+        return rand.New(rand.NewSource(time.Now().UnixNano()))
+    }
+    return rng
+}
+
+// Shuffles items in place (Fisher-Yates). Pass a seeded rng for
+// reproducible results, nil uses a time-seeded one.
+func Shuffle[I any](items []I, rng *rand.Rand) {
+    rng = randOrDefault(rng)
+    for i := len(items) - 1; i > 0; i-- {
+        j := rng.Intn(i + 1)
+        items[i], items[j] = items[j], items[i]
+    }
+}
+
+// Returns n randomly chosen items without replacement (reservoir
+// sampling). If n >= len(items), all items are returned in random order.
+func Sample[I any](items []I, n int, rng *rand.Rand) []I {
+    rng = randOrDefault(rng)
+    n = clampLength(n, len(items))
+
+    reservoir := append(make([]I, 0, n), items[:n]...)
+    for i := n; i < len(items); i++ {
+        if j := rng.Intn(i + 1); j < n {
+            reservoir[j] = items[i]
+        }
+    }
+
+    Shuffle(reservoir, rng)
+    return reservoir
+}
+
+
+///////////////////////////////////////////////////////
+// Listing 106: Die n größten und kleinsten Elemente //
+///////////////////////////////////////////////////////
+
+// Returns the n items with the largest keys, largest first. Keeps only the
+// n best items seen so far in a min-heap, so it needs O(m log n) instead of
+// O(m log m) for sorting all m items.
+func TopN[I any, O constraints.Ordered](items []I, n int, keyFn func(I) O) []I {
+    return selectN(items, n, keyFn, func(lhs, rhs O) bool { return lhs < rhs })
+}
+
+// Returns the n items with the smallest keys, smallest first
+func BottomN[I any, O constraints.Ordered](items []I, n int, keyFn func(I) O) []I {
+    return selectN(items, n, keyFn, func(lhs, rhs O) bool { return lhs > rhs })
+}
+
+// worse returns true if the first key is worse than the second one. The
+// heap's root is always the worst of the selected items.
+func selectN[I any, O constraints.Ordered](items []I, n int, keyFn func(I) O, worse func(O, O) bool) []I {
+    n = clampLength(n, len(items))
+    if n == 0 {
+        return []I{}
+    }
+
+    selected := NewPriorityQueue(func(lhs, rhs Pair[O, I]) bool { return worse(lhs.First, rhs.First) })
+    for _, item := range items {
+        candidate := Pair[O, I]{First: keyFn(item), Second: item}
+        if selected.Len() < n {
+            selected.Push(candidate)
+        } else if worst, _ := selected.Peek(); worse(worst.First, candidate.First) {
+            selected.Pop()
+            selected.Push(candidate)
+        }
+    }
+
+    // Popping returns the worst item first, so fill the result from the end
+    result := make([]I, selected.Len())
+    for index := len(result) - 1; index >= 0; index-- {
+        best, _ := selected.Pop()
+        result[index] = best.Second
+    }
+    return result
+}
+
+func main() {
+    sizedItems := []sizedLentil{ /*...*/ }
+    /* ... */
+
+    for _, item := range TopN(sizedItems, 3, func(item sizedLentil) int { return item.size() }) {
+        fmt.Println("Size:", item.size())
+    }
+}
+
+
+/////////////////////////////////////////////
+// Listing 107: Häufigkeiten von Elementen //
+/////////////////////////////////////////////
+
+// Counts how often each item occurs. In contrast to genericItemsBag, items
+// do not need to be consecutive to be counted together.
+func Frequencies[I comparable](items []I) map[I]int {
+    result := make(map[I]int)
+    for _, item := range items {
+        result[item]++
+    }
+    return result
+}
+
+// Like Frequencies, but returns the counts ordered from most to least
+// frequent. Items with the same count are ordered by first occurrence.
+func FrequenciesSorted[I comparable](items []I) []Pair[I, int] {
+    counts := Frequencies(items)
+    result := Map(Distinct(items), func(item I) Pair[I, int] { return Pair[I, int]{First: item, Second: counts[item]} })
+    StableSort(result, func(pair Pair[I, int]) int { return -pair.Second })
+    return result
+}
+
+func main() {
+    sizedItems := []sizedLentil{ /*...*/ }
+    /* ... */
+
+    // Histogram of lentil sizes
+    sizes := Map(sizedItems, func(item sizedLentil) int { return item.size() })
+    for _, bucket := range FrequenciesSorted(sizes) {
+        fmt.Println("Size:", bucket.First, "Count:", bucket.Second)
+    }
+}
+
+
+///////////////////////////////////////////////
+// Listing 108: Mengenoperationen auf Slices //
+///////////////////////////////////////////////
+
+// Returns the items contained in both a and b in the order of a. Like all
+// set operations below, the result contains no duplicates.
+func Intersect[I comparable](a, b []I) []I {
+    inB := NewSet(b...)
+    return process(Distinct(a), inB.Contains)
+}
+
+// Returns all items of a followed by the items of b not contained in a
+func Union[I comparable](a, b []I) []I {
+    return Distinct(Concat(a, b))
+}
+
+// Returns the items of a that are not contained in b
+func Difference[I comparable](a, b []I) []I {
+    inB := NewSet(b...)
+    return process(Distinct(a), func(item I) bool { return !inB.Contains(item) })
+}
+
+
+///////////////////////////////////////
+// Listing 109: Kartesisches Produkt //
+///////////////////////////////////////
+
+// Returns all pairs of items of a and b in row-major order: all items of b
+// combined with a[0], then with a[1], and so on.
+func CartesianProduct[A, B any](a []A, b []B) []Pair[A, B] {
+    result := make([]Pair[A, B], 0, len(a)*len(b))
+    for _, first := range a {
+        for _, second := range b {
+            result = append(result, Pair[A, B]{First: first, Second: second})
+        }
+    }
+    return result
+}
+
+// Returns all pairs of items of a, except pairs of an item with itself
+// (same index)
+func CartesianProductSelf[A any](a []A) []Pair[A, A] {
+    if len(a) == 0 {
+        return []Pair[A, A]{}
+    }
+
+    result := make([]Pair[A, A], 0, len(a)*(len(a)-1))
+    for i, first := range a {
+        for j, second := range a {
+            if i != j {
+                result = append(result, Pair[A, A]{First: first, Second: second})
+            }
+        }
+    }
+    return result
+}
+
+func main() {
+    // Test a predicate for all combinations of lentils and snails
+    lentils := []lentil{{isGood: true}, {isGood: false}}
+    snails := []snail{{hasHouse: true}, {hasHouse: false}}
+    for _, pair := range CartesianProduct(lentils, snails) {
+        fmt.Println(pair, "Both eaten:", pair.First.shouldEat() && pair.Second.shouldEat())
+    }
+}
+
+
+//////////////////////////////////////////////////
+// Listing 110: Permutationen und Kombinationen //
+//////////////////////////////////////////////////
+
+// Returns all n! orderings of items (backtracking). Each permutation is an
+// independent slice.
+func Permutations[I any](items []I) [][]I {
+    result := [][]I{}
+    current := make([]I, 0, len(items))
+    used := make([]bool, len(items))
+
+    var backtrack func()
+    backtrack = func() {
+        if len(current) == len(items) {
+            result = append(result, append([]I{}, current...))
+            return
+        }
+        for index, item := range items {
+            if used[index] {
+                continue
+            }
+            used[index] = true
+            current = append(current, item)
+            backtrack()
+            current = current[:len(current)-1]
+            used[index] = false
+        }
+    }
+
+    backtrack()
+    return result
+}
+
+// Returns all subsets of k items, each keeping the original order of items.
+// Note that k is an ordinary parameter, type parameters cannot be values.
+func Combinations[I any](items []I, k int) [][]I {
+    result := [][]I{}
+    if k < 0 || k > len(items) {
+        return result
+    }
+    current := make([]I, 0, k)
+
+    var backtrack func(start int)
+    backtrack = func(start int) {
+        if len(current) == k {
+            result = append(result, append([]I{}, current...))
+            return
+        }
+        // Stop early if there are not enough items left to fill current
+        for index := start; index <= len(items)-(k-len(current)); index++ {
+            current = append(current, items[index])
+            backtrack(index + 1)
+            current = current[:len(current)-1]
+        }
+    }
+
+    backtrack(0)
+    return result
+}
+
+// Lazy variant of Permutations, returns one permutation per call and false
+// when all have been returned. Memory usage is O(n) instead of O(n * n!).
+func PermutationsIter[I any](items []I) func() ([]I, bool) {
+    // Permutations of the indices are generated in lexicographic order
+    indices := make([]int, len(items))
+    for i := range indices {
+        indices[i] = i
+    }
+    first, done := true, false
+
+    return func() ([]I, bool) {
+        if done {
+            return nil, false
+        }
+        if !first && !nextPermutation(indices) {
+            done = true
+            return nil, false
+        }
+        first = false
+        return Map(indices, func(index int) I { return items[index] }), true
+    }
+}
+
+// Rearranges indices into the lexicographically next permutation. Returns
+// false if indices already is the last one.
+func nextPermutation(indices []int) bool {
+    // Find the rightmost position whose successor is larger
+    pivot := len(indices) - 2
+    for pivot >= 0 && indices[pivot] >= indices[pivot+1] {
+        pivot--
+    }
+    if pivot < 0 {
+        return false
+    }
+
+    // Swap with the rightmost larger element and reverse the tail
+    successor := len(indices) - 1
+    for indices[successor] <= indices[pivot] {
+        successor--
+    }
+    indices[pivot], indices[successor] = indices[successor], indices[pivot]
+    for i, j := pivot+1, len(indices)-1; i < j; i, j = i+1, j-1 {
+        indices[i], indices[j] = indices[j], indices[i]
+    }
+    return true
+}
+
+func main() {
+    sizedItems := []sizedEatOrKeep{
+        sizedLentil{lentilSize: LARGE, lentil: lentil{isGood: true}},
+        sizedLentil{lentilSize: MEDIUM, lentil: lentil{isGood: false}},
+        sizedLentil{lentilSize: SMALL, lentil: lentil{isGood: true}},
+    }
+
+    // processAndSort must return the same order for every input order
+    next := PermutationsIter(sizedItems)
+    for permutation, ok := next(); ok; permutation, ok = next() {
+        processedOrderd := processAndSort(permutation, func(item sizedEatOrKeep) bool { return !item.shouldEat() })
+        fmt.Println("Smallest:", processedOrderd[0].size())
+    }
+}
+
+
+////////////////////////////////////////
+// Listing 111: Generische Iteratoren //
+////////////////////////////////////////
+
+// Pull-based iteration, allows processing items lazily one by one instead
+// of materializing slices
+type Iterator[T any] interface {
+    // Returns the next item and false if there are no more items
+    Next() (T, bool)
+}
+
+func NewSliceIterator[T any](items []T) Iterator[T] {
+    return &sliceIterator[T]{items: items}
+}
+
+type filterIterator[T any] struct {
+    inner     Iterator[T]
+    predicate func(T) bool
+}
+
+// Lazy version of process, only returns items satisfying predicate
+func NewFilterIterator[T any](inner Iterator[T], predicate func(T) bool) Iterator[T] {
+    return &filterIterator[T]{inner: inner, predicate: predicate}
+}
+
+func (it *filterIterator[T]) Next() (T, bool) {
+    for {
+        item, ok := it.inner.Next()
+        if !ok || it.predicate(item) {
+            return item, ok
+        }
+    }
+}
+
+type mapIterator[I, O any] struct {
+    inner     Iterator[I]
+    transform func(I) O
+}
+
+// Lazy version of Map
+func NewMapIterator[I, O any](inner Iterator[I], transform func(I) O) Iterator[O] {
+    return &mapIterator[I, O]{inner: inner, transform: transform}
+}
+
+func (it *mapIterator[I, O]) Next() (O, bool) {
+    item, ok := it.inner.Next()
+    if !ok {
+        var zero O
+        return zero, false
+    }
+    return it.transform(item), true
+}
+
+// Reads all remaining items into a slice
+func Collect[T any](iter Iterator[T]) []T {
+    result := []T{}
+    for item, ok := iter.Next(); ok; item, ok = iter.Next() {
+        result = append(result, item)
+    }
+    return result
+}
+
+func main() {
+    sizedItems := []sizedEatOrKeep{ /*...*/ }
+    /* ... */
+
+    // Sizes are only calculated for items that are actually read
+    sizes := NewMapIterator(
+        NewFilterIterator(NewSliceIterator(sizedItems), func(item sizedEatOrKeep) bool { return !item.shouldEat() }),
+        func(item sizedEatOrKeep) int { return item.size() })
+    fmt.Println("Sizes:", Collect(sizes))
+}
+
+
+///////////////////////////////////////////
+// Listing 112: Weitere Iterator-Adapter //
+///////////////////////////////////////////
+
+type limitIterator[T any] struct {
+    inner     Iterator[T]
+    remaining int
+}
+
+// Stops after returning n items
+func NewLimitIterator[T any](inner Iterator[T], n int) Iterator[T] {
+    return &limitIterator[T]{inner: inner, remaining: n}
+}
+
+func (it *limitIterator[T]) Next() (T, bool) {
+    if it.remaining <= 0 {
+        var zero T
+        return zero, false
+    }
+    it.remaining--
+    return it.inner.Next()
+}
+
+type chainIterator[T any] struct {
+    iters []Iterator[T]
+}
+
+// Returns all items of the first iterator, then all of the second, and so on
+func NewChainIterator[T any](iters ...Iterator[T]) Iterator[T] {
+    return &chainIterator[T]{iters: iters}
+}
+
+func (it *chainIterator[T]) Next() (T, bool) {
+    for len(it.iters) > 0 {
+        if item, ok := it.iters[0].Next(); ok {
+            return item, true
+        }
+        it.iters = it.iters[1:]
+    }
+
+    var zero T
+    return zero, false
+}
+
+type channelIterator[T any] struct {
+    ch <-chan T
+}
+
+// Returns the items received from ch until it is closed. Next blocks while
+// waiting for the next item.
+func NewChannelIterator[T any](ch <-chan T) Iterator[T] {
+    return channelIterator[T]{ch: ch}
+}
+
+func (it channelIterator[T]) Next() (T, bool) {
+    item, ok := <-it.ch
+    return item, ok
+}
+
+type infiniteIterator[T any] struct {
+    generator func() T
+}
+
+// Returns an unbounded number of items created by generator. Combine it with
+// NewLimitIterator to avoid endless loops.
+func NewInfiniteIterator[T any](generator func() T) Iterator[T] {
+    return infiniteIterator[T]{generator: generator}
+}
+
+func (it infiniteIterator[T]) Next() (T, bool) {
+    return it.generator(), true
+}
+
+func main() {
+    items := []eatOrKeep{ /*...*/ }
+    /* ... */
+
+    // Kept items from a slice and a channel, followed by an endless supply
+    // of good lentils, limited to 10 items
+    in := make(chan eatOrKeep)
+    /* ... */
+    source := NewChainIterator(
+        NewSliceIterator(items),
+        NewChannelIterator[eatOrKeep](in),
+        NewInfiniteIterator(func() eatOrKeep { return lentil{isGood: true} }))
+    kept := NewFilterIterator(source, func(item eatOrKeep) bool { return !item.shouldEat() })
+    fmt.Println("Kept:", len(Collect(NewLimitIterator(kept, 10))))
+}